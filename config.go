@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/tnagatomi/gh-portrait/internal/config"
+)
+
+// runConfig runs the config subcommand, printing the effective configuration,
+// and returns the process exit code
+func runConfig(cfg config.Config, args []string) int {
+	if len(args) != 0 {
+		fmt.Fprintln(os.Stderr, usage)
+		return 1
+	}
+
+	data, err := cfg.Marshal()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if path, err := config.Path(); err == nil {
+		fmt.Printf("# %s\n", path)
+	}
+	fmt.Print(string(data))
+
+	return 0
+}
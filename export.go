@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tnagatomi/gh-portrait/internal/export"
+	"github.com/tnagatomi/gh-portrait/internal/github"
+)
+
+// exportOptions holds the arguments of the export subcommand
+type exportOptions struct {
+	username string
+	format   string
+	tab      string
+	details  bool
+}
+
+// parseExportArgs parses the arguments of the export subcommand. Flags may
+// come before or after the username.
+func parseExportArgs(args []string) (exportOptions, error) {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	var opts exportOptions
+	flags.StringVar(&opts.format, "format", "csv", "output format (csv or tsv)")
+	flags.StringVar(&opts.tab, "tab", "owning", "repository tab to export (pinned, owning or contributed)")
+	flags.BoolVar(&opts.details, "details", false, "include forks, license, topics and dates")
+
+	if err := flags.Parse(args); err != nil {
+		return exportOptions{}, err
+	}
+	if flags.NArg() == 0 {
+		return exportOptions{}, errors.New("username is required")
+	}
+	opts.username = flags.Arg(0)
+	if err := flags.Parse(flags.Args()[1:]); err != nil {
+		return exportOptions{}, err
+	}
+	if flags.NArg() != 0 {
+		return exportOptions{}, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	return opts, nil
+}
+
+// runExport runs the export subcommand and returns the process exit code
+func runExport(args []string) int {
+	opts, err := parseExportArgs(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, usage)
+		return 1
+	}
+
+	outputFormat, err := export.ParseFormat(opts.format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	repos, err := github.FetchAllRepositories(context.Background(), opts.username, opts.tab)
+	if err != nil {
+		printError(opts.username, err)
+		return 1
+	}
+
+	if err := export.Write(os.Stdout, repos, outputFormat, opts.details); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,60 @@
+package main
+
+import "testing"
+
+func TestParseExportArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    exportOptions
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			args: []string{"alice"},
+			want: exportOptions{username: "alice", format: "csv", tab: "owning"},
+		},
+		{
+			name: "flags before the username",
+			args: []string{"--format", "tsv", "--details", "alice"},
+			want: exportOptions{username: "alice", format: "tsv", tab: "owning", details: true},
+		},
+		{
+			name: "flags after the username",
+			args: []string{"alice", "--format", "tsv", "--tab", "pinned"},
+			want: exportOptions{username: "alice", format: "tsv", tab: "pinned"},
+		},
+		{
+			name: "flags around the username",
+			args: []string{"--tab", "contributed", "alice", "--details"},
+			want: exportOptions{username: "alice", format: "csv", tab: "contributed", details: true},
+		},
+		{
+			name:    "no username",
+			args:    []string{"--format", "tsv"},
+			wantErr: true,
+		},
+		{
+			name:    "two usernames",
+			args:    []string{"alice", "bob"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"alice", "--verbose"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseExportArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseExportArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseExportArgs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Tab names that can appear in the tab bar
+const (
+	TabInfo        = "info"
+	TabPinned      = "pinned"
+	TabOwning      = "owning"
+	TabContributed = "contributed"
+)
+
+// maxPageSize is the largest page size accepted by the GitHub GraphQL API
+const maxPageSize = 100
+
+// knownTabs lists every tab in its default order
+var knownTabs = []string{TabInfo, TabPinned, TabOwning, TabContributed}
+
+// Config represents the user configuration
+type Config struct {
+	DefaultTab   string           `yaml:"default_tab"`
+	Tabs         []string         `yaml:"tabs,flow"`
+	PageSize     PageSize         `yaml:"page_size"`
+	CacheTTL     time.Duration    `yaml:"cache_ttl"`
+	Prefetch     bool             `yaml:"prefetch"`
+	Watch        time.Duration    `yaml:"watch"`
+	Facets       Facets           `yaml:"facets"`
+	SplitWidth   int              `yaml:"split_width"`
+	Avatar       string           `yaml:"avatar"` // Protocol drawing the avatar, or none
+	READMEImages bool             `yaml:"readme_images"`
+	Renderer     Renderer         `yaml:"renderer"`
+	Theme        string           `yaml:"theme"`
+	Themes       map[string]Theme `yaml:"themes,omitempty"`
+	Keys         Keys             `yaml:"keys"`
+}
+
+// PageSize holds the number of repositories fetched for each paginated tab
+type PageSize struct {
+	Owning      int `yaml:"owning"`
+	Contributed int `yaml:"contributed"`
+}
+
+// Facets holds the repository kinds hidden from repository lists by default
+type Facets struct {
+	HideForks     bool `yaml:"hide_forks"`
+	HideArchived  bool `yaml:"hide_archived"`
+	HideTemplates bool `yaml:"hide_templates"`
+	HideMirrors   bool `yaml:"hide_mirrors"`
+}
+
+// Renderer holds the markdown renderer options
+type Renderer struct {
+	Style         string `yaml:"style"`      // Glamour style name or path to a JSON style
+	CodeTheme     string `yaml:"code_theme"` // Chroma theme for code blocks, empty for the style's own
+	WrapWidth     int    `yaml:"wrap_width"` // Widest READMEs are wrapped at, 0 for the terminal width
+	Emoji         bool   `yaml:"emoji"`
+	LinkFootnotes bool   `yaml:"link_footnotes"`
+}
+
+// Theme holds a user-defined theme. Unset values are taken from the base theme.
+type Theme struct {
+	Base    string `yaml:"base,omitempty"` // Built-in theme to start from
+	Accent  string `yaml:"accent,omitempty"`
+	Muted   string `yaml:"muted,omitempty"`
+	Subtle  string `yaml:"subtle,omitempty"`
+	Error   string `yaml:"error,omitempty"`
+	Glamour string `yaml:"glamour,omitempty"` // Glamour style used for READMEs
+}
+
+// Keys holds the key bindings. Each action accepts one or more keys using the
+// names reported by Bubble Tea, e.g. "ctrl+c", "left" or "l".
+type Keys struct {
+	Quit       []string `yaml:"quit,flow"`
+	NextTab    []string `yaml:"next_tab,flow"`
+	PrevTab    []string `yaml:"prev_tab,flow"`
+	Up         []string `yaml:"up,flow"`
+	Down       []string `yaml:"down,flow"`
+	Open       []string `yaml:"open,flow"`
+	Filter     []string `yaml:"filter,flow"`
+	Sort       []string `yaml:"sort,flow"`
+	Facets     []string `yaml:"facets,flow"`
+	Refresh    []string `yaml:"refresh,flow"`
+	RefreshAll []string `yaml:"refresh_all,flow"`
+	Focus      []string `yaml:"focus,flow"`
+	NextLink   []string `yaml:"next_link,flow"`
+	PrevLink   []string `yaml:"prev_link,flow"`
+	Copy       []string `yaml:"copy,flow"`
+	Contents   []string `yaml:"contents,flow"`
+	NextMatch  []string `yaml:"next_match,flow"`
+	PrevMatch  []string `yaml:"prev_match,flow"`
+	Help       []string `yaml:"help,flow"`
+}
+
+// actions returns the key bindings indexed by their config name
+func (k Keys) actions() map[string][]string {
+	return map[string][]string{
+		"quit":        k.Quit,
+		"next_tab":    k.NextTab,
+		"prev_tab":    k.PrevTab,
+		"up":          k.Up,
+		"down":        k.Down,
+		"open":        k.Open,
+		"filter":      k.Filter,
+		"sort":        k.Sort,
+		"facets":      k.Facets,
+		"refresh":     k.Refresh,
+		"refresh_all": k.RefreshAll,
+		"focus":       k.Focus,
+		"next_link":   k.NextLink,
+		"prev_link":   k.PrevLink,
+		"copy":        k.Copy,
+		"contents":    k.Contents,
+		"next_match":  k.NextMatch,
+		"prev_match":  k.PrevMatch,
+		"help":        k.Help,
+	}
+}
+
+// Default returns the configuration used when no config file exists
+func Default() Config {
+	return Config{
+		DefaultTab: TabInfo,
+		Tabs:       append([]string{}, knownTabs...),
+		PageSize: PageSize{
+			Owning:      30,
+			Contributed: 30,
+		},
+		CacheTTL:     0,
+		Prefetch:     true,
+		SplitWidth:   120,
+		Avatar:       "auto",
+		READMEImages: true,
+		Renderer: Renderer{
+			Style: "auto",
+			Emoji: true,
+		},
+		Theme: "auto",
+		Keys: Keys{
+			Quit:       []string{"q", "ctrl+c", "esc"},
+			NextTab:    []string{"right", "l"},
+			PrevTab:    []string{"left", "h"},
+			Up:         []string{"up", "k"},
+			Down:       []string{"down", "j"},
+			Open:       []string{"enter"},
+			Filter:     []string{"/"},
+			Sort:       []string{"s"},
+			Facets:     []string{"f"},
+			Refresh:    []string{"r"},
+			RefreshAll: []string{"R"},
+			Focus:      []string{"w"},
+			NextLink:   []string{"]"},
+			PrevLink:   []string{"["},
+			Copy:       []string{"y"},
+			Contents:   []string{"t"},
+			NextMatch:  []string{"n"},
+			PrevMatch:  []string{"N"},
+			Help:       []string{"?"},
+		},
+	}
+}
+
+// Path returns the location of the config file, following the XDG base directory
+// specification
+func Path() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "gh-portrait", "config.yml"), nil
+}
+
+// Load reads the config file, falling back to defaults when it does not exist
+func Load() (Config, error) {
+	path, err := Path()
+	if err != nil {
+		return Config{}, err
+	}
+	return LoadFile(path)
+}
+
+// LoadFile reads the config file at path, falling back to defaults when it does not exist
+func LoadFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Default(), nil
+	}
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg, err := Parse(data)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse parses YAML config data on top of the defaults and validates the result
+func Parse(data []byte) (Config, error) {
+	cfg := Default()
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// Validate checks that the configuration values are usable. Names of themes, code
+// themes and image protocols are checked by the UI, which knows them.
+func (c Config) Validate() error {
+	if len(c.Tabs) == 0 {
+		return errors.New("tabs: at least one tab is required")
+	}
+
+	seen := make(map[string]bool, len(c.Tabs))
+	for _, tab := range c.Tabs {
+		if !isKnownTab(tab) {
+			return fmt.Errorf("tabs: unknown tab %q", tab)
+		}
+		if seen[tab] {
+			return fmt.Errorf("tabs: duplicate tab %q", tab)
+		}
+		seen[tab] = true
+	}
+
+	if !seen[c.DefaultTab] {
+		return fmt.Errorf("default_tab: %q is not one of the configured tabs", c.DefaultTab)
+	}
+
+	if c.PageSize.Owning < 1 || c.PageSize.Owning > maxPageSize {
+		return fmt.Errorf("page_size.owning: must be between 1 and %d", maxPageSize)
+	}
+	if c.PageSize.Contributed < 1 || c.PageSize.Contributed > maxPageSize {
+		return fmt.Errorf("page_size.contributed: must be between 1 and %d", maxPageSize)
+	}
+
+	if c.CacheTTL < 0 {
+		return errors.New("cache_ttl: must not be negative")
+	}
+	if c.Watch < 0 {
+		return errors.New("watch: must not be negative")
+	}
+	if c.SplitWidth < 0 {
+		return errors.New("split_width: must not be negative")
+	}
+
+	if c.Renderer.WrapWidth < 0 {
+		return errors.New("renderer.wrap_width: must not be negative")
+	}
+	if err := c.Keys.validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validate checks that every action has a key and no key is bound twice
+func (k Keys) validate() error {
+	actions := k.actions()
+	names := make([]string, 0, len(actions))
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	boundTo := make(map[string]string)
+	for _, name := range names {
+		keys := actions[name]
+		if len(keys) == 0 {
+			return fmt.Errorf("keys.%s: at least one key is required", name)
+		}
+		for _, key := range keys {
+			if other, ok := boundTo[key]; ok {
+				return fmt.Errorf("keys.%s: %q is already bound to %s", name, key, other)
+			}
+			boundTo[key] = name
+		}
+	}
+	return nil
+}
+
+// Marshal encodes the configuration as YAML
+func (c Config) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// isKnownTab reports whether name is a supported tab
+func isKnownTab(name string) bool {
+	for _, tab := range knownTabs {
+		if tab == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,249 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    func() Config
+		wantErr bool
+	}{
+		{
+			name: "empty config uses defaults",
+			data: "",
+			want: Default,
+		},
+		{
+			name: "overrides",
+			data: `
+default_tab: owning
+tabs: [owning, contributed, info]
+page_size:
+  owning: 50
+cache_ttl: 10m
+renderer:
+  style: dark
+  emoji: false
+`,
+			want: func() Config {
+				cfg := Default()
+				cfg.DefaultTab = TabOwning
+				cfg.Tabs = []string{TabOwning, TabContributed, TabInfo}
+				cfg.PageSize.Owning = 50
+				cfg.CacheTTL = 10 * time.Minute
+				cfg.Renderer = Renderer{Style: "dark", Emoji: false}
+				return cfg
+			},
+		},
+		{
+			name:    "unknown tab",
+			data:    "tabs: [info, stars]",
+			wantErr: true,
+		},
+		{
+			name:    "duplicate tab",
+			data:    "tabs: [info, info]",
+			wantErr: true,
+		},
+		{
+			name:    "default tab not shown",
+			data:    "tabs: [info]\ndefault_tab: pinned",
+			wantErr: true,
+		},
+		{
+			name:    "page size too large",
+			data:    "page_size:\n  contributed: 101",
+			wantErr: true,
+		},
+		{
+			name: "renderer options",
+			data: "renderer:\n  code_theme: monokai\n  wrap_width: 100\n  link_footnotes: true",
+			want: func() Config {
+				cfg := Default()
+				cfg.Renderer.CodeTheme = "monokai"
+				cfg.Renderer.WrapWidth = 100
+				cfg.Renderer.LinkFootnotes = true
+				return cfg
+			},
+		},
+		{
+			name:    "negative wrap width",
+			data:    "renderer:\n  wrap_width: -1",
+			wantErr: true,
+		},
+		{
+			name:    "negative cache ttl",
+			data:    "cache_ttl: -1s",
+			wantErr: true,
+		},
+		{
+			name: "custom theme",
+			data: `
+theme: solarized
+themes:
+  solarized:
+    base: dark
+    accent: "#268bd2"
+`,
+			want: func() Config {
+				cfg := Default()
+				cfg.Theme = "solarized"
+				cfg.Themes = map[string]Theme{
+					"solarized": {Base: "dark", Accent: "#268bd2"},
+				}
+				return cfg
+			},
+		},
+		{
+			name: "watch interval",
+			data: "watch: 5m",
+			want: func() Config {
+				cfg := Default()
+				cfg.Watch = 5 * time.Minute
+				return cfg
+			},
+		},
+		{
+			name:    "negative watch interval",
+			data:    "watch: -1m",
+			wantErr: true,
+		},
+		{
+			name: "split layout disabled",
+			data: "split_width: 0",
+			want: func() Config {
+				cfg := Default()
+				cfg.SplitWidth = 0
+				return cfg
+			},
+		},
+		{
+			name:    "negative split width",
+			data:    "split_width: -1",
+			wantErr: true,
+		},
+		{
+			name: "avatar disabled",
+			data: "avatar: none",
+			want: func() Config {
+				cfg := Default()
+				cfg.Avatar = "none"
+				return cfg
+			},
+		},
+		{
+			name: "README images disabled",
+			data: "readme_images: false",
+			want: func() Config {
+				cfg := Default()
+				cfg.READMEImages = false
+				return cfg
+			},
+		},
+		{
+			name: "prefetch disabled",
+			data: "prefetch: false",
+			want: func() Config {
+				cfg := Default()
+				cfg.Prefetch = false
+				return cfg
+			},
+		},
+		{
+			name: "facets",
+			data: "facets:\n  hide_forks: true\n  hide_archived: true",
+			want: func() Config {
+				cfg := Default()
+				cfg.Facets.HideForks = true
+				cfg.Facets.HideArchived = true
+				return cfg
+			},
+		},
+		{
+			name: "key bindings",
+			data: "keys:\n  next_tab: [tab]\n  prev_tab: [shift+tab]",
+			want: func() Config {
+				cfg := Default()
+				cfg.Keys.NextTab = []string{"tab"}
+				cfg.Keys.PrevTab = []string{"shift+tab"}
+				return cfg
+			},
+		},
+		{
+			name:    "empty key binding",
+			data:    "keys:\n  quit: []",
+			wantErr: true,
+		},
+		{
+			name:    "key bound twice",
+			data:    "keys:\n  help: [q]",
+			wantErr: true,
+		},
+		{
+			name:    "invalid yaml",
+			data:    "tabs: [info",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if want := tt.want(); !reflect.DeepEqual(got, want) {
+				t.Errorf("Parse() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestLoadFileMissing(t *testing.T) {
+	got, err := LoadFile(filepath.Join(t.TempDir(), "config.yml"))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, Default()) {
+		t.Errorf("LoadFile() = %+v, want defaults", got)
+	}
+}
+
+func TestPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+
+	got, err := Path()
+	if err != nil {
+		t.Fatalf("Path() error = %v", err)
+	}
+	if want := filepath.Join("/tmp/xdg", "gh-portrait", "config.yml"); got != want {
+		t.Errorf("Path() = %v, want %v", got, want)
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	cfg := Default()
+	cfg.CacheTTL = 5 * time.Minute
+
+	data, err := cfg.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	got, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, cfg) {
+		t.Errorf("round trip = %+v, want %+v", got, cfg)
+	}
+}
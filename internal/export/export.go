@@ -0,0 +1,102 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tnagatomi/gh-portrait/internal/github"
+)
+
+// Format represents an export output format
+type Format string
+
+const (
+	FormatCSV Format = "csv"
+	FormatTSV Format = "tsv"
+)
+
+var (
+	// baseHeader lists the columns written for every repository
+	baseHeader = []string{"owner", "name", "language", "stars", "url", "description"}
+
+	// detailHeader lists the additional columns written when details are requested
+	detailHeader = []string{"forks", "fork", "archived", "template", "mirror", "license", "topics", "created_at", "pushed_at"}
+)
+
+// ParseFormat converts a format name into a Format
+func ParseFormat(name string) (Format, error) {
+	switch Format(strings.ToLower(name)) {
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatTSV:
+		return FormatTSV, nil
+	default:
+		return "", fmt.Errorf("unsupported format: %s (expected csv or tsv)", name)
+	}
+}
+
+// Write writes the repositories to w as delimited rows preceded by a header row.
+// When details is true, fork count, flag, license, topic and date columns are appended.
+func Write(w io.Writer, repos []github.Repository, format Format, details bool) error {
+	writer := csv.NewWriter(w)
+	if format == FormatTSV {
+		writer.Comma = '\t'
+	}
+
+	header := baseHeader
+	if details {
+		header = append(append([]string{}, baseHeader...), detailHeader...)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, repo := range repos {
+		if err := writer.Write(record(repo, details)); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// record converts a repository into a row of fields
+func record(repo github.Repository, details bool) []string {
+	row := []string{
+		repo.Owner,
+		repo.Name,
+		repo.Language,
+		strconv.Itoa(repo.StarCount),
+		repo.URL,
+		repo.Description,
+	}
+
+	if details {
+		row = append(row,
+			strconv.Itoa(repo.ForkCount),
+			strconv.FormatBool(repo.IsFork),
+			strconv.FormatBool(repo.IsArchived),
+			strconv.FormatBool(repo.IsTemplate),
+			strconv.FormatBool(repo.IsMirror),
+			repo.License,
+			strings.Join(repo.Topics, " "),
+			formatTime(repo.CreatedAt),
+			formatTime(repo.PushedAt),
+		)
+	}
+
+	return row
+}
+
+// formatTime formats a timestamp as RFC 3339, leaving unknown times empty
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
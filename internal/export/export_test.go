@@ -0,0 +1,91 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/tnagatomi/gh-portrait/internal/github"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Format
+		wantErr bool
+	}{
+		{name: "csv", input: "csv", want: FormatCSV},
+		{name: "tsv", input: "tsv", want: FormatTSV},
+		{name: "upper case", input: "CSV", want: FormatCSV},
+		{name: "unknown", input: "json", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFormat(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFormat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	repos := []github.Repository{
+		{
+			Owner:       "tnagatomi",
+			Name:        "gh-portrait",
+			Language:    "Go",
+			StarCount:   42,
+			URL:         "https://github.com/tnagatomi/gh-portrait",
+			Description: "GitHub profile, in your terminal",
+			ForkCount:   3,
+			License:     "MIT",
+			Topics:      []string{"cli", "tui"},
+			CreatedAt:   time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+	}
+
+	tests := []struct {
+		name    string
+		format  Format
+		details bool
+		want    string
+	}{
+		{
+			name:   "csv",
+			format: FormatCSV,
+			want: "owner,name,language,stars,url,description\n" +
+				"tnagatomi,gh-portrait,Go,42,https://github.com/tnagatomi/gh-portrait,\"GitHub profile, in your terminal\"\n",
+		},
+		{
+			name:   "tsv",
+			format: FormatTSV,
+			want: "owner\tname\tlanguage\tstars\turl\tdescription\n" +
+				"tnagatomi\tgh-portrait\tGo\t42\thttps://github.com/tnagatomi/gh-portrait\tGitHub profile, in your terminal\n",
+		},
+		{
+			name:    "csv with details",
+			format:  FormatCSV,
+			details: true,
+			want: "owner,name,language,stars,url,description,forks,fork,archived,template,mirror,license,topics,created_at,pushed_at\n" +
+				"tnagatomi,gh-portrait,Go,42,https://github.com/tnagatomi/gh-portrait,\"GitHub profile, in your terminal\",3,false,false,false,false,MIT,cli tui,2025-01-02T03:04:05Z,\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, repos, tt.format, tt.details); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Write() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
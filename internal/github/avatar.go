@@ -0,0 +1,128 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	_ "image/jpeg" // Decode JPEG images
+	_ "image/png"  // Decode PNG images
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+const (
+	// avatarCacheTTL is how long downloaded avatars and images are cached,
+	// independently of the API responses
+	avatarCacheTTL = 24 * time.Hour
+
+	// MaxImageSize is the size of the largest image downloaded by FetchImage
+	MaxImageSize = 5 << 20
+)
+
+var (
+	// ErrUnsupportedImage is returned by FetchImage for images that cannot be drawn
+	// in the terminal
+	ErrUnsupportedImage = errors.New("unsupported image")
+
+	// ErrSVGImage is returned by FetchImage for SVG images
+	ErrSVGImage = fmt.Errorf("%w: SVG", ErrUnsupportedImage)
+
+	// ErrAnimatedImage is returned by FetchImage for animated GIF images
+	ErrAnimatedImage = fmt.Errorf("%w: animated", ErrUnsupportedImage)
+
+	// ErrImageTooLarge is returned by FetchImage for images larger than MaxImageSize
+	ErrImageTooLarge = fmt.Errorf("%w: larger than %d MB", ErrUnsupportedImage, MaxImageSize>>20)
+)
+
+// FetchAvatar downloads and decodes the avatar image at the URL. Avatars are
+// cached on disk.
+func FetchAvatar(ctx context.Context, url string) (image.Image, error) {
+	data, _, err := download(ctx, url, MaxImageSize)
+	if err != nil {
+		return nil, fmt.Errorf("downloading avatar: %w", err)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decoding avatar: %w", err)
+	}
+	return img, nil
+}
+
+// FetchImage downloads and decodes the image at the URL, such as an image of a
+// README. Images are cached on disk. SVG images, animated images and images larger
+// than MaxImageSize are reported with an error wrapping ErrUnsupportedImage.
+func FetchImage(ctx context.Context, url string) (image.Image, error) {
+	data, contentType, err := download(ctx, url, MaxImageSize)
+	if err != nil {
+		return nil, err
+	}
+	return decodeImage(data, contentType)
+}
+
+// decodeImage decodes a downloaded image of the given content type, reporting
+// images that cannot be drawn with an error wrapping ErrUnsupportedImage
+func decodeImage(data []byte, contentType string) (image.Image, error) {
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "image/svg+xml" {
+		return nil, ErrSVGImage
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedImage, contentType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decoding image: %w", err)
+	}
+	if format == "gif" {
+		if anim, err := gif.DecodeAll(bytes.NewReader(data)); err == nil && len(anim.Image) > 1 {
+			return nil, ErrAnimatedImage
+		}
+	}
+	return img, nil
+}
+
+// download returns the content at the URL and its type, or ErrImageTooLarge when
+// it is larger than maxSize bytes
+func download(ctx context.Context, url string, maxSize int64) ([]byte, string, error) {
+	client, err := api.NewHTTPClient(api.ClientOptions{
+		EnableCache: true,
+		CacheTTL:    avatarCacheTTL,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", errors.New(resp.Status)
+	}
+	if resp.ContentLength > maxSize {
+		return nil, "", ErrImageTooLarge
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, "", ErrImageTooLarge
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
@@ -0,0 +1,78 @@
+package github
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+func TestDecodeImage(t *testing.T) {
+	frame := image.NewPaletted(image.Rect(0, 0, 2, 2), color.Palette{color.Black, color.White})
+
+	var pngData bytes.Buffer
+	if err := png.Encode(&pngData, frame); err != nil {
+		t.Fatal(err)
+	}
+	var stillGIF, animatedGIF bytes.Buffer
+	if err := gif.EncodeAll(&stillGIF, &gif.GIF{Image: []*image.Paletted{frame}, Delay: []int{0}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := gif.EncodeAll(&animatedGIF, &gif.GIF{Image: []*image.Paletted{frame, frame}, Delay: []int{10, 10}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		wantErr     error
+	}{
+		{
+			name:        "PNG",
+			data:        pngData.Bytes(),
+			contentType: "image/png",
+		},
+		{
+			name:        "still GIF",
+			data:        stillGIF.Bytes(),
+			contentType: "image/gif",
+		},
+		{
+			name:        "animated GIF",
+			data:        animatedGIF.Bytes(),
+			contentType: "image/gif",
+			wantErr:     ErrAnimatedImage,
+		},
+		{
+			name:        "SVG",
+			data:        []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`),
+			contentType: "image/svg+xml; charset=utf-8",
+			wantErr:     ErrSVGImage,
+		},
+		{
+			name:        "unknown format",
+			data:        []byte("RIFF....WEBP"),
+			contentType: "image/webp",
+			wantErr:     ErrUnsupportedImage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := decodeImage(tt.data, tt.contentType)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("decodeImage() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && img.Bounds().Dx() != 2 {
+				t.Errorf("decodeImage() width = %v, want %v", img.Bounds().Dx(), 2)
+			}
+			if tt.wantErr != nil && !errors.Is(err, ErrUnsupportedImage) {
+				t.Errorf("decodeImage() error = %v, want it to wrap %v", err, ErrUnsupportedImage)
+			}
+		})
+	}
+}
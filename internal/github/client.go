@@ -0,0 +1,37 @@
+package github
+
+import (
+	"context"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// clientOptions are the options used to create GraphQL clients
+var clientOptions api.ClientOptions
+
+// SetCacheTTL enables caching of API responses for the given duration.
+// A zero duration disables caching.
+func SetCacheTTL(ttl time.Duration) {
+	clientOptions.EnableCache = ttl > 0
+	clientOptions.CacheTTL = ttl
+}
+
+// refreshKey marks contexts whose requests skip cached responses
+type refreshKey struct{}
+
+// WithRefresh returns a context whose requests fetch fresh data instead of cached
+// responses. The fresh responses are cached for later requests.
+func WithRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, refreshKey{}, true)
+}
+
+// newGraphQLClient creates a GraphQL client using the configured options
+func newGraphQLClient(ctx context.Context) (*api.GraphQLClient, error) {
+	opts := clientOptions
+	if refresh, _ := ctx.Value(refreshKey{}).(bool); refresh && opts.EnableCache {
+		// Cached responses are always older than a nanosecond, so they are refetched
+		opts.Headers = map[string]string{"X-GH-CACHE-TTL": "1ns"}
+	}
+	return api.NewGraphQLClient(opts)
+}
@@ -0,0 +1,98 @@
+package github
+
+import (
+	"context"
+
+	graphql "github.com/cli/shurcooL-graphql"
+)
+
+// Profile is a user together with the repositories shown on the initial screen
+type Profile struct {
+	User                *User
+	Pinned              []Repository
+	Owning              []Repository
+	OwningPageInfo      PageInfo
+	Contributed         []Repository
+	ContributedPageInfo PageInfo
+}
+
+// ProfileOptions controls the first page of the repository lists fetched with a
+// profile. Only First, Sort and ExcludeForks are used. Skipped lists are not
+// queried and left empty.
+type ProfileOptions struct {
+	Owning          PageOptions
+	Contributed     PageOptions
+	SkipPinned      bool
+	SkipOwning      bool
+	SkipContributed bool
+}
+
+// FetchProfile fetches a user's profile, pinned repositories and the first page of
+// their owned and contributed repositories in a single query. Later pages are
+// fetched with FetchOwningRepositoriesPage and FetchContributedRepositoriesPage.
+func FetchProfile(ctx context.Context, login string, opts ProfileOptions) (*Profile, error) {
+	client, err := newGraphQLClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var query struct {
+		User struct {
+			userNode
+			PinnedItems struct {
+				Nodes []struct {
+					Repository repositoryNode `graphql:"... on Repository"`
+				}
+			} `graphql:"pinnedItems(first: 6, types: REPOSITORY) @include(if: $withPinned)"`
+			Owning struct {
+				Nodes    []repositoryNode
+				PageInfo pageInfoNode
+			} `graphql:"owning: repositories(first: $owningFirst, isFork: $isFork, ownerAffiliations: OWNER, privacy: PUBLIC, orderBy: $owningOrderBy) @include(if: $withOwning)"`
+			Contributed struct {
+				Nodes    []repositoryNode
+				PageInfo pageInfoNode
+			} `graphql:"contributed: repositoriesContributedTo(first: $contributedFirst, includeUserRepositories: false, contributionTypes: [COMMIT, PULL_REQUEST, REPOSITORY], privacy: PUBLIC, orderBy: $contributedOrderBy) @include(if: $withContributed)"`
+		} `graphql:"user(login: $login)"`
+	}
+
+	variables := map[string]interface{}{
+		"login":              graphql.String(login),
+		"owningFirst":        pageSize(opts.Owning.First),
+		"owningOrderBy":      opts.Owning.Sort.order(),
+		"isFork":             isForkFilter(opts.Owning),
+		"contributedFirst":   pageSize(opts.Contributed.First),
+		"contributedOrderBy": opts.Contributed.Sort.order(),
+		"withPinned":         graphql.Boolean(!opts.SkipPinned),
+		"withOwning":         graphql.Boolean(!opts.SkipOwning),
+		"withContributed":    graphql.Boolean(!opts.SkipContributed),
+	}
+
+	err = client.Query("FetchProfile", &query, variables)
+	if err = ignoreMissingREADME(err); err != nil {
+		return nil, err
+	}
+
+	profile := &Profile{
+		User:                query.User.toUser(),
+		Pinned:              make([]Repository, 0, len(query.User.PinnedItems.Nodes)),
+		Owning:              make([]Repository, 0, len(query.User.Owning.Nodes)),
+		OwningPageInfo:      query.User.Owning.PageInfo.toPageInfo(),
+		Contributed:         make([]Repository, 0, len(query.User.Contributed.Nodes)),
+		ContributedPageInfo: query.User.Contributed.PageInfo.toPageInfo(),
+	}
+	for _, node := range query.User.PinnedItems.Nodes {
+		profile.Pinned = append(profile.Pinned, node.Repository.toRepository())
+	}
+	for _, node := range query.User.Owning.Nodes {
+		profile.Owning = append(profile.Owning, node.toRepository())
+	}
+	for _, node := range query.User.Contributed.Nodes {
+		profile.Contributed = append(profile.Contributed, node.toRepository())
+	}
+
+	// Apply orderings the API does not support, as the page fetchers do
+	SortRepositories(profile.Owning, opts.Owning.Sort)
+	SortRepositories(profile.Contributed, opts.Contributed.Sort)
+
+	return profile, nil
+}
@@ -2,12 +2,20 @@ package github
 
 import (
 	"context"
-	"sort"
+	"fmt"
+	"time"
 
-	"github.com/cli/go-gh/v2/pkg/api"
 	"github.com/cli/shurcooL-graphql"
 )
 
+const (
+	// defaultPageSize is the number of repositories shown in a repository tab
+	defaultPageSize = 30
+
+	// maxPageSize is the largest page size accepted by the GitHub GraphQL API
+	maxPageSize = 100
+)
+
 // Repository represents a GitHub repository
 type Repository struct {
 	Owner       string
@@ -16,11 +24,141 @@ type Repository struct {
 	URL         string
 	StarCount   int
 	Language    string
+	ForkCount   int
+	IsFork      bool
+	IsArchived  bool
+	IsTemplate  bool
+	IsMirror    bool
+	License     string
+	Topics      []string
+	CreatedAt   time.Time
+	PushedAt    time.Time
+}
+
+// PageInfo describes the position of a page within a paginated repository list
+type PageInfo struct {
+	EndCursor   string
+	HasNextPage bool
+}
+
+// PageOptions controls which page of a repository list is fetched
+type PageOptions struct {
+	First        int       // Number of repositories to fetch
+	After        string    // Cursor to continue from; empty for the first page
+	Sort         SortField // Order of the repositories; stars when empty
+	ExcludeForks bool      // Whether forks are left out; only supported for owned repositories
+}
+
+// repositoryNode is the set of repository fields requested by every query
+type repositoryNode struct {
+	Owner struct {
+		Login graphql.String
+	}
+	Name            graphql.String
+	Description     graphql.String
+	URL             graphql.String
+	StargazerCount  graphql.Int
+	ForkCount       graphql.Int
+	IsFork          graphql.Boolean
+	IsArchived      graphql.Boolean
+	IsTemplate      graphql.Boolean
+	IsMirror        graphql.Boolean
+	CreatedAt       time.Time
+	PushedAt        time.Time
+	PrimaryLanguage struct {
+		Name graphql.String
+	}
+	LicenseInfo struct {
+		SpdxID graphql.String `graphql:"spdxId"`
+	}
+	RepositoryTopics struct {
+		Nodes []struct {
+			Topic struct {
+				Name graphql.String
+			}
+		}
+	} `graphql:"repositoryTopics(first: 10)"`
+}
+
+// pageInfoNode is the pagination information returned for a connection
+type pageInfoNode struct {
+	EndCursor   graphql.String
+	HasNextPage graphql.Boolean
+}
+
+// toRepository converts the GraphQL node into a Repository
+func (n repositoryNode) toRepository() Repository {
+	topics := make([]string, 0, len(n.RepositoryTopics.Nodes))
+	for _, node := range n.RepositoryTopics.Nodes {
+		topics = append(topics, string(node.Topic.Name))
+	}
+
+	return Repository{
+		Owner:       string(n.Owner.Login),
+		Name:        string(n.Name),
+		Description: string(n.Description),
+		URL:         string(n.URL),
+		StarCount:   int(n.StargazerCount),
+		Language:    string(n.PrimaryLanguage.Name),
+		ForkCount:   int(n.ForkCount),
+		IsFork:      bool(n.IsFork),
+		IsArchived:  bool(n.IsArchived),
+		IsTemplate:  bool(n.IsTemplate),
+		IsMirror:    bool(n.IsMirror),
+		License:     string(n.LicenseInfo.SpdxID),
+		Topics:      topics,
+		CreatedAt:   n.CreatedAt,
+		PushedAt:    n.PushedAt,
+	}
+}
+
+// toPageInfo converts the GraphQL page info into a PageInfo
+func (p pageInfoNode) toPageInfo() PageInfo {
+	return PageInfo{
+		EndCursor:   string(p.EndCursor),
+		HasNextPage: bool(p.HasNextPage),
+	}
+}
+
+// pageSize returns the number of repositories to request for a page, applying the
+// default and the API limit
+func pageSize(first int) graphql.Int {
+	if first <= 0 {
+		first = defaultPageSize
+	}
+	if first > maxPageSize {
+		first = maxPageSize
+	}
+	return graphql.Int(first)
+}
+
+// pageVariables returns the GraphQL variables for the given page options
+func pageVariables(login string, opts PageOptions) map[string]interface{} {
+	var after *graphql.String
+	if opts.After != "" {
+		after = graphql.NewString(graphql.String(opts.After))
+	}
+
+	return map[string]interface{}{
+		"login":   graphql.String(login),
+		"first":   pageSize(opts.First),
+		"after":   after,
+		"orderBy": opts.Sort.order(),
+	}
+}
+
+// isForkFilter returns the isFork argument for owned repositories, which is null
+// unless forks are excluded
+func isForkFilter(opts PageOptions) *graphql.Boolean {
+	if opts.ExcludeForks {
+		return graphql.NewBoolean(false)
+	}
+	return nil
 }
 
 // FetchPinnedRepositories fetches a user's pinned repositories
 func FetchPinnedRepositories(ctx context.Context, login string) ([]Repository, error) {
-	client, err := api.DefaultGraphQLClient()
+	client, err := newGraphQLClient(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -29,18 +167,7 @@ func FetchPinnedRepositories(ctx context.Context, login string) ([]Repository, e
 		User struct {
 			PinnedItems struct {
 				Nodes []struct {
-					Repository struct {
-						Owner struct {
-							Login graphql.String
-						}
-						Name        graphql.String
-						Description graphql.String
-						URL         graphql.String
-						StargazerCount graphql.Int
-						PrimaryLanguage struct {
-							Name graphql.String
-						}
-					} `graphql:"... on Repository"`
+					Repository repositoryNode `graphql:"... on Repository"`
 				}
 			} `graphql:"pinnedItems(first: 6, types: REPOSITORY)"`
 		} `graphql:"user(login: $login)"`
@@ -57,14 +184,7 @@ func FetchPinnedRepositories(ctx context.Context, login string) ([]Repository, e
 
 	repos := make([]Repository, 0, len(query.User.PinnedItems.Nodes))
 	for _, node := range query.User.PinnedItems.Nodes {
-		repos = append(repos, Repository{
-			Owner:       string(node.Repository.Owner.Login),
-			Name:        string(node.Repository.Name),
-			Description: string(node.Repository.Description),
-			URL:         string(node.Repository.URL),
-			StarCount:   int(node.Repository.StargazerCount),
-			Language:    string(node.Repository.PrimaryLanguage.Name),
-		})
+		repos = append(repos, node.Repository.toRepository())
 	}
 
 	return repos, nil
@@ -72,105 +192,121 @@ func FetchPinnedRepositories(ctx context.Context, login string) ([]Repository, e
 
 // FetchOwningRepositories fetches a user's most starred repositories that they own
 func FetchOwningRepositories(ctx context.Context, login string) ([]Repository, error) {
-	client, err := api.DefaultGraphQLClient()
+	repos, _, err := FetchOwningRepositoriesPage(ctx, login, PageOptions{First: defaultPageSize})
+	return repos, err
+}
+
+// FetchOwningRepositoriesPage fetches a single page of the repositories a user owns,
+// in the order given by the page options
+func FetchOwningRepositoriesPage(ctx context.Context, login string, opts PageOptions) ([]Repository, PageInfo, error) {
+	client, err := newGraphQLClient(ctx)
 	if err != nil {
-		return nil, err
+		return nil, PageInfo{}, err
 	}
 
 	var query struct {
 		User struct {
 			Repositories struct {
-				Nodes []struct {
-					Owner struct {
-						Login graphql.String
-					}
-					Name           graphql.String
-					Description    graphql.String
-					URL            graphql.String
-					StargazerCount graphql.Int
-					PrimaryLanguage struct {
-						Name graphql.String
-					}
-				}
-			} `graphql:"repositories(first: 30, ownerAffiliations: OWNER, privacy: PUBLIC, orderBy: {field: STARGAZERS, direction: DESC})"`
+				Nodes    []repositoryNode
+				PageInfo pageInfoNode
+			} `graphql:"repositories(first: $first, after: $after, isFork: $isFork, ownerAffiliations: OWNER, privacy: PUBLIC, orderBy: $orderBy)"`
 		} `graphql:"user(login: $login)"`
 	}
 
-	variables := map[string]interface{}{
-		"login": graphql.String(login),
-	}
+	variables := pageVariables(login, opts)
+	variables["isFork"] = isForkFilter(opts)
 
 	err = client.Query("FetchOwningRepositories", &query, variables)
 	if err != nil {
-		return nil, err
+		return nil, PageInfo{}, err
 	}
 
 	repos := make([]Repository, 0, len(query.User.Repositories.Nodes))
 	for _, node := range query.User.Repositories.Nodes {
-		repos = append(repos, Repository{
-			Owner:       string(node.Owner.Login),
-			Name:        string(node.Name),
-			Description: string(node.Description),
-			URL:         string(node.URL),
-			StarCount:   int(node.StargazerCount),
-			Language:    string(node.PrimaryLanguage.Name),
-		})
+		repos = append(repos, node.toRepository())
 	}
 
-	return repos, nil
+	// Apply orderings the API does not support
+	SortRepositories(repos, opts.Sort)
+
+	return repos, query.User.Repositories.PageInfo.toPageInfo(), nil
 }
 
 // FetchContributedRepositories fetches repositories that the user has contributed to
 func FetchContributedRepositories(ctx context.Context, login string) ([]Repository, error) {
-	client, err := api.DefaultGraphQLClient()
+	repos, _, err := FetchContributedRepositoriesPage(ctx, login, PageOptions{First: defaultPageSize})
+	return repos, err
+}
+
+// FetchContributedRepositoriesPage fetches a single page of the repositories that the
+// user has contributed to, in the order given by the page options
+func FetchContributedRepositoriesPage(ctx context.Context, login string, opts PageOptions) ([]Repository, PageInfo, error) {
+	client, err := newGraphQLClient(ctx)
 	if err != nil {
-		return nil, err
+		return nil, PageInfo{}, err
 	}
 
 	var query struct {
 		User struct {
 			RepositoriesContributedTo struct {
-				Nodes []struct {
-					Owner struct {
-						Login graphql.String
-					}
-					Name           graphql.String
-					Description    graphql.String
-					URL            graphql.String
-					StargazerCount graphql.Int
-					PrimaryLanguage struct {
-						Name graphql.String
-					}
-				}
-			} `graphql:"repositoriesContributedTo(first: 30, includeUserRepositories: false, contributionTypes: [COMMIT, PULL_REQUEST, REPOSITORY], privacy: PUBLIC, orderBy: {field: STARGAZERS, direction: DESC})"`
+				Nodes    []repositoryNode
+				PageInfo pageInfoNode
+			} `graphql:"repositoriesContributedTo(first: $first, after: $after, includeUserRepositories: false, contributionTypes: [COMMIT, PULL_REQUEST, REPOSITORY], privacy: PUBLIC, orderBy: $orderBy)"`
 		} `graphql:"user(login: $login)"`
 	}
 
-	variables := map[string]interface{}{
-		"login": graphql.String(login),
-	}
-
-	err = client.Query("FetchContributedRepositories", &query, variables)
+	err = client.Query("FetchContributedRepositories", &query, pageVariables(login, opts))
 	if err != nil {
-		return nil, err
+		return nil, PageInfo{}, err
 	}
 
 	repos := make([]Repository, 0, len(query.User.RepositoriesContributedTo.Nodes))
 	for _, node := range query.User.RepositoriesContributedTo.Nodes {
-		repos = append(repos, Repository{
-			Owner:       string(node.Owner.Login),
-			Name:        string(node.Name),
-			Description: string(node.Description),
-			URL:         string(node.URL),
-			StarCount:   int(node.StargazerCount),
-			Language:    string(node.PrimaryLanguage.Name),
-		})
-	}
-
-	// Sort repositories by star count in descending order
-	sort.Slice(repos, func(i, j int) bool {
-		return repos[i].StarCount > repos[j].StarCount
-	})
+		repos = append(repos, node.toRepository())
+	}
+
+	// The API does not reliably order contributed repositories, so sort them here
+	SortRepositories(repos, opts.Sort)
+
+	return repos, query.User.RepositoriesContributedTo.PageInfo.toPageInfo(), nil
+}
+
+// FetchAllRepositories fetches every repository of the given list ("pinned", "owning"
+// or "contributed"), following pagination until the last page
+func FetchAllRepositories(ctx context.Context, login string, listType string) ([]Repository, error) {
+	var fetchPage func(context.Context, string, PageOptions) ([]Repository, PageInfo, error)
+
+	switch listType {
+	case "pinned":
+		return FetchPinnedRepositories(ctx, login)
+	case "owning":
+		fetchPage = FetchOwningRepositoriesPage
+	case "contributed":
+		fetchPage = FetchContributedRepositoriesPage
+	default:
+		return nil, fmt.Errorf("unknown repository list: %s", listType)
+	}
+
+	var (
+		repos []Repository
+		opts  = PageOptions{First: maxPageSize}
+	)
+	for {
+		page, pageInfo, err := fetchPage(ctx, login, opts)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, page...)
+
+		if !pageInfo.HasNextPage || pageInfo.EndCursor == "" {
+			break
+		}
+		opts.After = pageInfo.EndCursor
+	}
+
+	if listType == "contributed" {
+		SortRepositories(repos, opts.Sort)
+	}
 
 	return repos, nil
 }
@@ -0,0 +1,87 @@
+package github
+
+import (
+	"sort"
+	"strings"
+)
+
+// SortField identifies the order of a repository list
+type SortField string
+
+const (
+	SortStars   SortField = "stars"
+	SortForks   SortField = "forks"
+	SortPushed  SortField = "pushed"
+	SortCreated SortField = "created"
+	SortName    SortField = "name"
+)
+
+// SortFields lists the sort fields in the order they are cycled through
+var SortFields = []SortField{SortStars, SortForks, SortPushed, SortCreated, SortName}
+
+// RepositoryOrder is the GraphQL input type used to order repository connections.
+// Its name must match the GraphQL type, as it is used to declare the query variable.
+type RepositoryOrder struct {
+	Field     string `json:"field"`
+	Direction string `json:"direction"`
+}
+
+// Label returns a human readable name of the sort field
+func (f SortField) Label() string {
+	switch f {
+	case SortPushed:
+		return "recently pushed"
+	case SortCreated:
+		return "recently created"
+	case "":
+		return string(SortStars)
+	default:
+		return string(f)
+	}
+}
+
+// ServerSide reports whether the GitHub API can order repositories by the field.
+// Fields that cannot be ordered by the API are sorted on the client only.
+func (f SortField) ServerSide() bool {
+	return f != SortForks
+}
+
+// order returns the GraphQL ordering for the field, falling back to stars for
+// fields the API cannot order by
+func (f SortField) order() RepositoryOrder {
+	switch f {
+	case SortPushed:
+		return RepositoryOrder{Field: "PUSHED_AT", Direction: "DESC"}
+	case SortCreated:
+		return RepositoryOrder{Field: "CREATED_AT", Direction: "DESC"}
+	case SortName:
+		return RepositoryOrder{Field: "NAME", Direction: "ASC"}
+	default:
+		return RepositoryOrder{Field: "STARGAZERS", Direction: "DESC"}
+	}
+}
+
+// SortRepositories sorts repositories in place by the given field. Counts and dates
+// are sorted in descending order and names in ascending order.
+func SortRepositories(repos []Repository, field SortField) {
+	var less func(a, b Repository) bool
+
+	switch field {
+	case SortForks:
+		less = func(a, b Repository) bool { return a.ForkCount > b.ForkCount }
+	case SortPushed:
+		less = func(a, b Repository) bool { return a.PushedAt.After(b.PushedAt) }
+	case SortCreated:
+		less = func(a, b Repository) bool { return a.CreatedAt.After(b.CreatedAt) }
+	case SortName:
+		less = func(a, b Repository) bool {
+			return strings.ToLower(a.Owner+"/"+a.Name) < strings.ToLower(b.Owner+"/"+b.Name)
+		}
+	default:
+		less = func(a, b Repository) bool { return a.StarCount > b.StarCount }
+	}
+
+	sort.SliceStable(repos, func(i, j int) bool {
+		return less(repos[i], repos[j])
+	})
+}
@@ -0,0 +1,45 @@
+package github
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSortRepositories(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	repos := []Repository{
+		{Owner: "a", Name: "beta", StarCount: 5, ForkCount: 1, PushedAt: day(3), CreatedAt: day(1)},
+		{Owner: "a", Name: "Alpha", StarCount: 10, ForkCount: 0, PushedAt: day(1), CreatedAt: day(2)},
+		{Owner: "a", Name: "gamma", StarCount: 1, ForkCount: 7, PushedAt: day(2), CreatedAt: day(3)},
+	}
+
+	tests := []struct {
+		field SortField
+		want  []string
+	}{
+		{field: SortStars, want: []string{"Alpha", "beta", "gamma"}},
+		{field: SortForks, want: []string{"gamma", "beta", "Alpha"}},
+		{field: SortPushed, want: []string{"beta", "gamma", "Alpha"}},
+		{field: SortCreated, want: []string{"gamma", "Alpha", "beta"}},
+		{field: SortName, want: []string{"Alpha", "beta", "gamma"}},
+		{field: "", want: []string{"Alpha", "beta", "gamma"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.field), func(t *testing.T) {
+			sorted := append([]Repository{}, repos...)
+			SortRepositories(sorted, tt.field)
+
+			got := make([]string, len(sorted))
+			for i, repo := range sorted {
+				got[i] = repo.Name
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortRepositories() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,23 +3,35 @@ package github
 import (
 	"context"
 	"strings"
+	"time"
 
-	"github.com/cli/go-gh/v2/pkg/api"
 	graphql "github.com/cli/shurcooL-graphql"
 )
 
 type User struct {
-	Login      string
-	Name       string
-	Bio        string
-	Pronouns   string
-	Company    string
-	Location   string
-	WebsiteURL string
-	Following  int
-	Followers  int
-	Social     []SocialAccount
-	README     *string // Nullable README content
+	Login              string
+	Name               string
+	Bio                string
+	Pronouns           string
+	Company            string
+	Location           string
+	WebsiteURL         string
+	AvatarURL          string
+	Email              string // Public email, empty when hidden
+	TwitterUsername    string
+	CreatedAt          time.Time
+	IsHireable         bool
+	HasSponsorsListing bool
+	Status             *UserStatus // Nullable status
+	Organizations      []Organization
+	IsViewer           bool // Whether the user is the authenticated user
+	IsFollowingViewer  bool // Whether the user follows the authenticated user
+	ViewerIsFollowing  bool // Whether the authenticated user follows the user
+	Following          int
+	Followers          int
+	Social             []SocialAccount
+	README             *string // Nullable README content
+	READMEBranch       string  // Default branch of the profile README repository
 }
 
 type SocialAccount struct {
@@ -27,85 +39,169 @@ type SocialAccount struct {
 	URL      string
 }
 
-func FetchUser(ctx context.Context, login string) (*User, error) {
-	client, err := api.DefaultGraphQLClient()
-	if err != nil {
-		return nil, err
-	}
+// UserStatus is the status set by the user
+type UserStatus struct {
+	Emoji   string // Emoji shortcode, e.g. ":rocket:"
+	Message string
+	Busy    bool // Whether the user indicated limited availability
+}
 
-	var query struct {
-		User struct {
-			Login      graphql.String
-			Name       graphql.String
-			Bio        graphql.String
-			Pronouns   graphql.String
-			Company    graphql.String
-			Location   graphql.String
-			WebsiteUrl graphql.String
-			Following  struct {
-				TotalCount graphql.Int
-			}
-			Followers struct {
-				TotalCount graphql.Int
-			}
-			SocialAccounts struct {
-				Nodes []struct {
-					Provider graphql.String
-					URL      graphql.String
-				}
-			} `graphql:"socialAccounts(first: 10)"`
-			Repository struct {
-				Object *struct {
-					Blob struct {
-						Text graphql.String
-					} `graphql:"... on Blob"`
-				} `graphql:"object(expression: \"HEAD:README.md\")"`
-			} `graphql:"repository(name: $login)"`
-		} `graphql:"user(login: $login)"`
-	}
+// Organization is an organization the user is a public member of
+type Organization struct {
+	Login     string
+	Name      string
+	AvatarURL string
+}
 
-	variables := map[string]interface{}{
-		"login": graphql.String(login),
+// userNode is the set of user fields requested by FetchUser and FetchProfile
+type userNode struct {
+	Login              graphql.String
+	Name               graphql.String
+	Bio                graphql.String
+	Pronouns           graphql.String
+	Company            graphql.String
+	Location           graphql.String
+	WebsiteUrl         graphql.String
+	AvatarUrl          graphql.String `graphql:"avatarUrl(size: 256)"`
+	Email              graphql.String
+	TwitterUsername    graphql.String
+	CreatedAt          time.Time
+	IsHireable         graphql.Boolean
+	HasSponsorsListing graphql.Boolean
+	Status             *struct {
+		Emoji                        graphql.String
+		Message                      graphql.String
+		IndicatesLimitedAvailability graphql.Boolean
 	}
-
-	err = client.Query("FetchUser", &query, variables)
-	if err != nil {
-		if strings.Contains(err.Error(), "Could not resolve to a Repository") {
-			// Ignore repository not found error
-			err = nil
-		} else {
-			return nil, err
+	Organizations struct {
+		Nodes []struct {
+			Login     graphql.String
+			Name      graphql.String
+			AvatarUrl graphql.String `graphql:"avatarUrl(size: 64)"`
 		}
+	} `graphql:"organizations(first: 10)"`
+	IsViewer          graphql.Boolean
+	IsFollowingViewer graphql.Boolean
+	ViewerIsFollowing graphql.Boolean
+	Following         struct {
+		TotalCount graphql.Int
+	}
+	Followers struct {
+		TotalCount graphql.Int
 	}
+	SocialAccounts struct {
+		Nodes []struct {
+			Provider graphql.String
+			URL      graphql.String
+		}
+	} `graphql:"socialAccounts(first: 10)"`
+	Repository struct {
+		DefaultBranchRef *struct {
+			Name graphql.String
+		}
+		Object *struct {
+			Blob struct {
+				Text graphql.String
+			} `graphql:"... on Blob"`
+		} `graphql:"object(expression: \"HEAD:README.md\")"`
+	} `graphql:"repository(name: $login)"`
+}
 
+// toUser converts the GraphQL node into a User
+func (n userNode) toUser() *User {
 	// Convert social accounts
-	social := make([]SocialAccount, 0, len(query.User.SocialAccounts.Nodes))
-	for _, node := range query.User.SocialAccounts.Nodes {
+	social := make([]SocialAccount, 0, len(n.SocialAccounts.Nodes))
+	for _, node := range n.SocialAccounts.Nodes {
 		social = append(social, SocialAccount{
 			Provider: string(node.Provider),
 			URL:      string(node.URL),
 		})
 	}
 
+	var status *UserStatus
+	if n.Status != nil {
+		status = &UserStatus{
+			Emoji:   string(n.Status.Emoji),
+			Message: string(n.Status.Message),
+			Busy:    bool(n.Status.IndicatesLimitedAvailability),
+		}
+	}
+
+	organizations := make([]Organization, 0, len(n.Organizations.Nodes))
+	for _, node := range n.Organizations.Nodes {
+		organizations = append(organizations, Organization{
+			Login:     string(node.Login),
+			Name:      string(node.Name),
+			AvatarURL: string(node.AvatarUrl),
+		})
+	}
+
 	// Get README if it exists
 	var readme *string
-	if query.User.Repository.Object != nil {
-		if text := string(query.User.Repository.Object.Blob.Text); text != "" {
+	if n.Repository.Object != nil {
+		if text := string(n.Repository.Object.Blob.Text); text != "" {
 			readme = &text
 		}
 	}
+	var readmeBranch string
+	if n.Repository.DefaultBranchRef != nil {
+		readmeBranch = string(n.Repository.DefaultBranchRef.Name)
+	}
 
 	return &User{
-		Login:      string(query.User.Login),
-		Name:       string(query.User.Name),
-		Bio:        string(query.User.Bio),
-		Pronouns:   string(query.User.Pronouns),
-		Company:    string(query.User.Company),
-		Location:   string(query.User.Location),
-		WebsiteURL: string(query.User.WebsiteUrl),
-		Following:  int(query.User.Following.TotalCount),
-		Followers:  int(query.User.Followers.TotalCount),
-		Social:     social,
-		README:     readme,
-	}, nil
+		Login:              string(n.Login),
+		Name:               string(n.Name),
+		Bio:                string(n.Bio),
+		Pronouns:           string(n.Pronouns),
+		Company:            string(n.Company),
+		Location:           string(n.Location),
+		WebsiteURL:         string(n.WebsiteUrl),
+		AvatarURL:          string(n.AvatarUrl),
+		Email:              string(n.Email),
+		TwitterUsername:    string(n.TwitterUsername),
+		CreatedAt:          n.CreatedAt,
+		IsHireable:         bool(n.IsHireable),
+		HasSponsorsListing: bool(n.HasSponsorsListing),
+		Status:             status,
+		Organizations:      organizations,
+		IsViewer:           bool(n.IsViewer),
+		IsFollowingViewer:  bool(n.IsFollowingViewer),
+		ViewerIsFollowing:  bool(n.ViewerIsFollowing),
+		Following:          int(n.Following.TotalCount),
+		Followers:          int(n.Followers.TotalCount),
+		Social:             social,
+		README:             readme,
+		READMEBranch:       readmeBranch,
+	}
+}
+
+// ignoreMissingREADME drops the error reported when the user has no profile
+// README repository, since the rest of the response is still usable
+func ignoreMissingREADME(err error) error {
+	if err != nil && strings.Contains(err.Error(), "Could not resolve to a Repository") {
+		return nil
+	}
+	return err
+}
+
+func FetchUser(ctx context.Context, login string) (*User, error) {
+	client, err := newGraphQLClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var query struct {
+		User userNode `graphql:"user(login: $login)"`
+	}
+
+	variables := map[string]interface{}{
+		"login": graphql.String(login),
+	}
+
+	err = client.Query("FetchUser", &query, variables)
+	if err = ignoreMissingREADME(err); err != nil {
+		return nil, err
+	}
+
+	return query.User.toUser(), nil
 }
@@ -0,0 +1,230 @@
+package termimage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi/kitty"
+)
+
+const (
+	// cellWidth and cellHeight approximate the size of a terminal cell in pixels,
+	// for protocols that draw the image pixel by pixel
+	cellWidth  = 10
+	cellHeight = 20
+
+	// kittyChunkSize is the largest payload of a kitty graphics command
+	kittyChunkSize = 4096
+
+	// saveCursor and restoreCursor keep the cursor where the image is drawn, since
+	// some protocols move it past the image
+	saveCursor    = "\x1b7"
+	restoreCursor = "\x1b8"
+)
+
+var (
+	// kittyImageID numbers the images transmitted with the kitty graphics protocol
+	kittyImageID atomic.Uint32
+
+	// kittyTransmitPattern matches the first command transmitting a kitty image,
+	// capturing its ID
+	kittyTransmitPattern = regexp.MustCompile(`\x1b_Ga=T,[^;]*\bi=(\d+)`)
+)
+
+// Release returns the escape sequences deleting the images transmitted by a view
+// from the terminal, which keeps kitty images until they are deleted. Views drawn
+// with other protocols leave nothing to delete.
+func Release(view string) string {
+	var b strings.Builder
+	for _, match := range kittyTransmitPattern.FindAllStringSubmatch(view, -1) {
+		if id, err := strconv.ParseUint(match[1], 10, 32); err == nil {
+			fmt.Fprintf(&b, "\x1b_Ga=d,d=I,q=2,i=%d\x1b\\", id)
+		}
+	}
+	return b.String()
+}
+
+// encodePNG scales the image to fit in cols by rows cells and encodes it as base64
+// PNG data. Large images are not sent at their full size just to be shrunk by the
+// terminal.
+func encodePNG(img image.Image, cols, rows int) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, fit(img, cols*cellWidth, rows*cellHeight)); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// renderKitty transmits the image with the kitty graphics protocol and draws it
+// with Unicode placeholders, which the terminal replaces with the image. Since the
+// placeholders are text, the image moves and goes away with the text around it.
+// Responses from the terminal are suppressed so that they do not show up as key
+// presses.
+func renderKitty(img image.Image, cols, rows int) (string, error) {
+	data, err := encodePNG(img, cols, rows)
+	if err != nil {
+		return "", err
+	}
+
+	// The image ID is the placeholders' foreground color, so it has 24 bits
+	id := kittyImageID.Add(1)%(1<<24-1) + 1
+
+	var b strings.Builder
+	for i := 0; i < len(data); i += kittyChunkSize {
+		chunk := data[i:min(i+kittyChunkSize, len(data))]
+		more := 0
+		if i+kittyChunkSize < len(data) {
+			more = 1
+		}
+		if i == 0 {
+			fmt.Fprintf(&b, "\x1b_Ga=T,U=1,f=100,q=2,i=%d,c=%d,r=%d,m=%d;%s\x1b\\", id, cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return b.String() + kittyPlaceholders(id, cols, rows), nil
+}
+
+// kittyPlaceholders returns cols by rows placeholder cells showing the image with
+// the ID. The diacritics of each cell give its row and column in the image.
+func kittyPlaceholders(id uint32, cols, rows int) string {
+	color := fmt.Sprintf("\x1b[38;2;%d;%d;%dm", id>>16&0xff, id>>8&0xff, id&0xff)
+	lines := make([]string, rows)
+	for row := range lines {
+		var b strings.Builder
+		b.WriteString(color)
+		for col := range cols {
+			b.WriteRune(kitty.Placeholder)
+			b.WriteRune(kitty.Diacritic(row))
+			b.WriteRune(kitty.Diacritic(col))
+		}
+		b.WriteString("\x1b[39m")
+		lines[row] = b.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderITerm2 draws the image with the iTerm2 inline images protocol
+func renderITerm2(img image.Image, cols, rows int) (string, error) {
+	data, err := encodePNG(img, cols, rows)
+	if err != nil {
+		return "", err
+	}
+
+	sequence := fmt.Sprintf("\x1b]1337;File=inline=1;width=%d;height=%d;preserveAspectRatio=1:%s\a", cols, rows, data)
+	return reserve(saveCursor+sequence+restoreCursor, cols, rows), nil
+}
+
+// renderHalfblocks draws the image with upper half blocks, whose foreground and
+// background colors are the top and bottom pixels of each cell. Transparent pixels
+// are left blank.
+func renderHalfblocks(img image.Image, cols, rows int) string {
+	scaled := fit(img, cols, rows*2)
+	bounds := scaled.Bounds()
+
+	lines := make([]string, rows)
+	for row := range lines {
+		var b strings.Builder
+		for col := range cols {
+			top := pixel(scaled, bounds.Min.X+col, bounds.Min.Y+row*2)
+			bottom := pixel(scaled, bounds.Min.X+col, bounds.Min.Y+row*2+1)
+			b.WriteString(halfblock(top, bottom))
+		}
+		lines[row] = b.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// halfblock renders a cell with the top and bottom colors, nil being transparent
+func halfblock(top, bottom *color.RGBA) string {
+	switch {
+	case top == nil && bottom == nil:
+		return " "
+	case top == nil:
+		return lipgloss.NewStyle().Foreground(hex(*bottom)).Render("▄")
+	case bottom == nil:
+		return lipgloss.NewStyle().Foreground(hex(*top)).Render("▀")
+	default:
+		return lipgloss.NewStyle().Foreground(hex(*top)).Background(hex(*bottom)).Render("▀")
+	}
+}
+
+// hex converts the color to a lipgloss color
+func hex(c color.RGBA) lipgloss.Color {
+	return lipgloss.Color(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B))
+}
+
+// pixel returns the color of the pixel, or nil when it is transparent or outside
+// the image
+func pixel(img *image.RGBA, x, y int) *color.RGBA {
+	if !(image.Point{X: x, Y: y}).In(img.Bounds()) {
+		return nil
+	}
+	c := img.RGBAAt(x, y)
+	if c.A < 128 {
+		return nil
+	}
+	// Undo the premultiplied alpha of partially transparent pixels
+	if c.A < 255 {
+		c.R = uint8(int(c.R) * 255 / int(c.A))
+		c.G = uint8(int(c.G) * 255 / int(c.A))
+		c.B = uint8(int(c.B) * 255 / int(c.A))
+	}
+	return &c
+}
+
+// fit scales the image to fit in width by height pixels, keeping its aspect ratio
+// and centering it. Each pixel is the average of the pixels it covers.
+func fit(img image.Image, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	src := img.Bounds()
+	if src.Empty() {
+		return dst
+	}
+
+	// Size of the scaled image inside the box
+	w, h := width, src.Dy()*width/src.Dx()
+	if h > height {
+		w, h = src.Dx()*height/src.Dy(), height
+	}
+	w, h = max(w, 1), max(h, 1)
+	offsetX, offsetY := (width-w)/2, (height-h)/2
+
+	for y := range h {
+		y0 := src.Min.Y + y*src.Dy()/h
+		y1 := max(src.Min.Y+(y+1)*src.Dy()/h, y0+1)
+		for x := range w {
+			x0 := src.Min.X + x*src.Dx()/w
+			x1 := max(src.Min.X+(x+1)*src.Dx()/w, x0+1)
+			dst.SetRGBA(offsetX+x, offsetY+y, average(img, x0, y0, x1, y1))
+		}
+	}
+	return dst
+}
+
+// average returns the average premultiplied color of the pixels in the rectangle
+func average(img image.Image, x0, y0, x1, y1 int) color.RGBA {
+	var r, g, b, a, n uint32
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			pr, pg, pb, pa := img.At(x, y).RGBA()
+			r, g, b, a = r+pr, g+pg, b+pb, a+pa
+			n++
+		}
+	}
+	return color.RGBA{
+		R: uint8(r / n >> 8),
+		G: uint8(g / n >> 8),
+		B: uint8(b / n >> 8),
+		A: uint8(a / n >> 8),
+	}
+}
@@ -0,0 +1,109 @@
+package termimage
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// sixelLevels is the number of levels of each color channel in the sixel palette,
+// giving a palette of sixelLevels³ colors
+const sixelLevels = 6
+
+// renderSixel draws the image as sixels, quantized to a fixed color cube.
+// Transparent pixels are left undrawn.
+func renderSixel(img image.Image, cols, rows int) string {
+	scaled := fit(img, cols*cellWidth, rows*cellHeight)
+	bounds := scaled.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	// Palette index of each pixel, -1 for transparent pixels
+	indexes := make([]int, width*height)
+	for y := range height {
+		for x := range width {
+			indexes[y*width+x] = paletteIndex(scaled, bounds.Min.X+x, bounds.Min.Y+y)
+		}
+	}
+
+	var b strings.Builder
+	// Pixels without sixels keep the background
+	fmt.Fprintf(&b, "\x1bP0;1;0q\"1;1;%d;%d", width, height)
+	for i := range sixelLevels * sixelLevels * sixelLevels {
+		r, g, bl := i/(sixelLevels*sixelLevels), i/sixelLevels%sixelLevels, i%sixelLevels
+		fmt.Fprintf(&b, "#%d;2;%d;%d;%d", i, r*100/(sixelLevels-1), g*100/(sixelLevels-1), bl*100/(sixelLevels-1))
+	}
+
+	// Each band is six pixels high, drawn once for every color it uses
+	for top := 0; top < height; top += 6 {
+		colors := bandColors(indexes, width, height, top)
+		for i, index := range colors {
+			fmt.Fprintf(&b, "#%d", index)
+			writeSixelRow(&b, indexes, width, height, top, index)
+			if i < len(colors)-1 {
+				b.WriteByte('$') // Back to the start of the band
+			}
+		}
+		b.WriteByte('-') // Next band
+	}
+	b.WriteString("\x1b\\")
+
+	return reserve(saveCursor+b.String()+restoreCursor, cols, rows)
+}
+
+// paletteIndex returns the index of the pixel's color in the color cube, or -1
+// when it is transparent
+func paletteIndex(img *image.RGBA, x, y int) int {
+	c := pixel(img, x, y)
+	if c == nil {
+		return -1
+	}
+	level := func(v uint8) int {
+		return (int(v)*(sixelLevels-1) + 127) / 255
+	}
+	return level(c.R)*sixelLevels*sixelLevels + level(c.G)*sixelLevels + level(c.B)
+}
+
+// bandColors returns the palette indexes used in the band starting at row top, in
+// order of first use
+func bandColors(indexes []int, width, height, top int) []int {
+	var colors []int
+	seen := make(map[int]bool)
+	for y := top; y < min(top+6, height); y++ {
+		for x := range width {
+			index := indexes[y*width+x]
+			if index >= 0 && !seen[index] {
+				seen[index] = true
+				colors = append(colors, index)
+			}
+		}
+	}
+	return colors
+}
+
+// writeSixelRow writes the sixels of one color in the band starting at row top,
+// compressing repeated sixels
+func writeSixelRow(b *strings.Builder, indexes []int, width, height, top, index int) {
+	sixel := func(x int) byte {
+		var bits byte
+		for bit := range 6 {
+			if y := top + bit; y < height && indexes[y*width+x] == index {
+				bits |= 1 << bit
+			}
+		}
+		return '?' + bits
+	}
+
+	for x := 0; x < width; {
+		c := sixel(x)
+		run := 1
+		for x+run < width && sixel(x+run) == c {
+			run++
+		}
+		if run > 3 {
+			fmt.Fprintf(b, "!%d%c", run, c)
+		} else {
+			b.WriteString(strings.Repeat(string(c), run))
+		}
+		x += run
+	}
+}
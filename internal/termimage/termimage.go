@@ -0,0 +1,116 @@
+// Package termimage renders images in the terminal using the graphics protocol the
+// terminal supports, falling back to Unicode half blocks.
+package termimage
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Protocol is a way of drawing images in the terminal
+type Protocol string
+
+const (
+	Auto       Protocol = "auto" // Detect the protocol from the environment
+	Kitty      Protocol = "kitty"
+	ITerm2     Protocol = "iterm2"
+	Sixel      Protocol = "sixel"
+	Halfblocks Protocol = "halfblocks" // Two pixels per cell using colored half blocks
+	None       Protocol = "none"       // Images are not drawn
+)
+
+// protocols lists every protocol that can be configured
+var protocols = []Protocol{Auto, Kitty, ITerm2, Sixel, Halfblocks, None}
+
+// IsValid reports whether the name is a known protocol
+func IsValid(name string) bool {
+	return slices.Contains(protocols, Protocol(name))
+}
+
+// Scrolls reports whether images drawn with the protocol are part of the text, so
+// that they scroll and clear with it. Kitty images are Unicode placeholders and half
+// blocks are text, while iTerm2 and sixel images are painted at the cursor.
+func (p Protocol) Scrolls() bool {
+	return p != ITerm2 && p != Sixel
+}
+
+// Resolve returns the protocol to use, detecting it from the environment for Auto
+func Resolve(p Protocol) Protocol {
+	if p == Auto {
+		return Detect(os.Getenv)
+	}
+	return p
+}
+
+// Detect guesses the best protocol supported by the terminal from its environment
+// variables. Terminals run inside tmux or screen fall back to half blocks since the
+// multiplexer does not pass images through.
+func Detect(getenv func(string) string) Protocol {
+	term, program := getenv("TERM"), getenv("TERM_PROGRAM")
+
+	switch {
+	case getenv("TMUX") != "" || strings.HasPrefix(term, "screen"):
+		return Halfblocks
+	case getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || program == "ghostty":
+		return Kitty
+	case program == "iTerm.app" || program == "WezTerm" || getenv("LC_TERMINAL") == "iTerm2":
+		return ITerm2
+	case strings.Contains(term, "sixel") || strings.HasPrefix(term, "foot") ||
+		term == "mlterm" || program == "mlterm":
+		return Sixel
+	default:
+		return Halfblocks
+	}
+}
+
+// Render draws the image in a box of cols by rows terminal cells. The result has
+// rows lines of cols cells each, so that it can be laid out like text.
+func Render(img image.Image, p Protocol, cols, rows int) (string, error) {
+	if cols <= 0 || rows <= 0 {
+		return "", fmt.Errorf("invalid size %dx%d", cols, rows)
+	}
+
+	switch p {
+	case Kitty:
+		return renderKitty(img, cols, rows)
+	case ITerm2:
+		return renderITerm2(img, cols, rows)
+	case Sixel:
+		return renderSixel(img, cols, rows), nil
+	case Halfblocks:
+		return renderHalfblocks(img, cols, rows), nil
+	default:
+		return "", fmt.Errorf("cannot render images with protocol %q", p)
+	}
+}
+
+// Cells returns the size in terminal cells of an image of width by height pixels
+// shown at about its own size, shrunk to fit within maxCols by maxRows cells while
+// keeping its aspect ratio
+func Cells(width, height, maxCols, maxRows int) (cols, rows int) {
+	if width <= 0 || height <= 0 {
+		return 0, 0
+	}
+	cols = min(maxCols, (width+cellWidth-1)/cellWidth)
+	rows = (cols*cellWidth*height + width*cellHeight - 1) / (width * cellHeight)
+	if rows > maxRows {
+		rows = maxRows
+		cols = max(1, rows*cellHeight*width/(height*cellWidth))
+	}
+	return max(0, cols), max(0, rows)
+}
+
+// reserve returns the escape sequence drawing the image followed by blank cells
+// taking up the cols by rows box. The terminal draws the image over the blanks.
+func reserve(sequence string, cols, rows int) string {
+	blank := strings.Repeat(" ", cols)
+	lines := make([]string, rows)
+	for i := range lines {
+		lines[i] = blank
+	}
+	lines[0] = sequence + blank
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,278 @@
+package termimage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi/kitty"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want Protocol
+	}{
+		{
+			name: "kitty",
+			env:  map[string]string{"TERM": "xterm-kitty", "KITTY_WINDOW_ID": "1"},
+			want: Kitty,
+		},
+		{
+			name: "ghostty",
+			env:  map[string]string{"TERM": "xterm-ghostty", "TERM_PROGRAM": "ghostty"},
+			want: Kitty,
+		},
+		{
+			name: "iTerm2",
+			env:  map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "iTerm.app"},
+			want: ITerm2,
+		},
+		{
+			name: "WezTerm",
+			env:  map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "WezTerm"},
+			want: ITerm2,
+		},
+		{
+			name: "foot",
+			env:  map[string]string{"TERM": "foot"},
+			want: Sixel,
+		},
+		{
+			name: "kitty inside tmux",
+			env:  map[string]string{"TERM": "tmux-256color", "KITTY_WINDOW_ID": "1", "TMUX": "/tmp/tmux-1000/default,1,0"},
+			want: Halfblocks,
+		},
+		{
+			name: "unknown terminal",
+			env:  map[string]string{"TERM": "xterm-256color"},
+			want: Halfblocks,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string {
+				return tt.env[key]
+			}
+			if got := Detect(getenv); got != tt.want {
+				t.Errorf("Detect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// newImage returns an image of the given size split into a red top half and a
+// transparent bottom half
+func newImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height / 2 {
+		for x := range width {
+			img.SetRGBA(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	return img
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol Protocol
+		prefix   string
+	}{
+		{
+			name:     "kitty",
+			protocol: Kitty,
+			prefix:   "\x1b_Ga=T,U=1,f=100",
+		},
+		{
+			name:     "iTerm2",
+			protocol: ITerm2,
+			prefix:   "\x1b7\x1b]1337;File=inline=1",
+		},
+		{
+			name:     "sixel",
+			protocol: Sixel,
+			prefix:   "\x1b7\x1bP0;1;0q",
+		},
+		{
+			name:     "half blocks",
+			protocol: Halfblocks,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(newImage(64, 64), tt.protocol, 8, 4)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if !strings.HasPrefix(got, tt.prefix) {
+				t.Errorf("Render() = %q, want prefix %q", got, tt.prefix)
+			}
+
+			// The image takes up the whole box like text would
+			if h := lipgloss.Height(got); h != 4 {
+				t.Errorf("Render() height = %v, want %v", h, 4)
+			}
+			for i, line := range strings.Split(got, "\n") {
+				if w := lipgloss.Width(line); w != 8 {
+					t.Errorf("Render() line %d width = %v, want %v", i, w, 8)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderKitty(t *testing.T) {
+	first, err := Render(newImage(64, 64), Kitty, 8, 4)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	second, err := Render(newImage(64, 64), Kitty, 8, 4)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	// Each image is transmitted with its own ID and drawn with placeholders
+	if first[:strings.Index(first, ";")] == second[:strings.Index(second, ";")] {
+		t.Errorf("Render() transmitted both images as %q", first[:strings.Index(first, ";")])
+	}
+	for i, line := range strings.Split(first, "\n") {
+		if n := strings.Count(line, string(kitty.Placeholder)); n != 8 {
+			t.Errorf("Render() line %d has %v placeholders, want %v", i, n, 8)
+		}
+	}
+}
+
+func TestRelease(t *testing.T) {
+	view, err := Render(newImage(64, 64), Kitty, 8, 4)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	id := view[strings.Index(view, ",i=")+3 : strings.Index(view, ",c=")]
+
+	want := "\x1b_Ga=d,d=I,q=2,i=" + id + "\x1b\\"
+	if got := Release(view + "\n" + view); got != want+want {
+		t.Errorf("Release() = %q, want %q", got, want+want)
+	}
+
+	halfblocks, err := Render(newImage(64, 64), Halfblocks, 8, 4)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if got := Release(halfblocks); got != "" {
+		t.Errorf("Release() of half blocks = %q, want empty", got)
+	}
+}
+
+func TestProtocolScrolls(t *testing.T) {
+	for _, p := range []Protocol{Kitty, Halfblocks, None} {
+		if !p.Scrolls() {
+			t.Errorf("%s.Scrolls() = false, want true", p)
+		}
+	}
+	for _, p := range []Protocol{ITerm2, Sixel} {
+		if p.Scrolls() {
+			t.Errorf("%s.Scrolls() = true, want false", p)
+		}
+	}
+}
+
+func TestEncodePNG(t *testing.T) {
+	data, err := encodePNG(newImage(4000, 2000), 8, 4)
+	if err != nil {
+		t.Fatalf("encodePNG() error = %v", err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		t.Fatalf("DecodeString() error = %v", err)
+	}
+	cfg, err := png.DecodeConfig(bytes.NewReader(decoded))
+	if err != nil {
+		t.Fatalf("DecodeConfig() error = %v", err)
+	}
+
+	// The image is scaled to the pixels of the box instead of sent at its full size
+	if cfg.Width != 8*cellWidth || cfg.Height != 4*cellHeight {
+		t.Errorf("encodePNG() size = %dx%d, want %dx%d", cfg.Width, cfg.Height, 8*cellWidth, 4*cellHeight)
+	}
+}
+
+func TestRenderHalfblocks(t *testing.T) {
+	got, err := Render(newImage(4, 4), Halfblocks, 4, 2)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	// The red top half fills the first row and the transparent bottom half is blank
+	lines := strings.Split(got, "\n")
+	if !strings.Contains(lines[0], "▀") {
+		t.Errorf("Render() first row = %q, want half blocks", lines[0])
+	}
+	if lines[1] != "    " {
+		t.Errorf("Render() second row = %q, want blank", lines[1])
+	}
+}
+
+func TestCells(t *testing.T) {
+	tests := []struct {
+		name               string
+		width, height      int
+		maxCols, maxRows   int
+		wantCols, wantRows int
+	}{
+		{
+			name:  "own size",
+			width: 400, height: 200,
+			maxCols: 80, maxRows: 20,
+			wantCols: 40, wantRows: 10,
+		},
+		{
+			name:  "wide banner shrunk to the width",
+			width: 1600, height: 400,
+			maxCols: 80, maxRows: 20,
+			wantCols: 80, wantRows: 10,
+		},
+		{
+			name:  "tall image shrunk to the height",
+			width: 400, height: 1600,
+			maxCols: 80, maxRows: 20,
+			wantCols: 10, wantRows: 20,
+		},
+		{
+			name:  "badge",
+			width: 90, height: 20,
+			maxCols: 80, maxRows: 20,
+			wantCols: 9, wantRows: 1,
+		},
+		{
+			name:    "empty image",
+			maxCols: 80, maxRows: 20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cols, rows := Cells(tt.width, tt.height, tt.maxCols, tt.maxRows)
+			if cols != tt.wantCols || rows != tt.wantRows {
+				t.Errorf("Cells() = %v, %v, want %v, %v", cols, rows, tt.wantCols, tt.wantRows)
+			}
+		})
+	}
+}
+
+func TestRenderInvalid(t *testing.T) {
+	if _, err := Render(newImage(4, 4), None, 4, 2); err == nil {
+		t.Error("Render() with no protocol error = nil, want error")
+	}
+	if _, err := Render(newImage(4, 4), Halfblocks, 0, 2); err == nil {
+		t.Error("Render() with no columns error = nil, want error")
+	}
+}
@@ -2,33 +2,138 @@ package ui
 
 import (
 	"context"
+	"fmt"
+	"image"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/cli/go-gh/v2/pkg/browser"
+	"github.com/tnagatomi/gh-portrait/internal/config"
 	"github.com/tnagatomi/gh-portrait/internal/github"
+	"github.com/tnagatomi/gh-portrait/internal/termimage"
 	"github.com/tnagatomi/gh-portrait/internal/ui/components"
+	"github.com/tnagatomi/gh-portrait/internal/ui/theme"
 )
 
 var (
-	dividerStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("241"))
-
-	errorStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("9"))
-
-	errorHelpStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("243"))
+	// tabTitles maps tab names to the titles shown in the tab bar
+	tabTitles = map[string]string{
+		config.TabInfo:        "Info",
+		config.TabPinned:      "Pinned",
+		config.TabOwning:      "Owning",
+		config.TabContributed: "Contributed",
+	}
 )
 
+// fetchProfileMsg is sent when the profile is fetched
+type fetchProfileMsg struct {
+	profile      *github.Profile
+	err          error
+	excludeForks bool
+}
+
 // fetchRepositoriesMsg is sent when repositories are fetched
 type fetchRepositoriesMsg struct {
 	repositories []github.Repository
-	err         error
-	tabIndex    int
+	pageInfo     github.PageInfo
+	err          error
+	tab          string
+	request      int
+	excludeForks bool
+}
+
+// fetchUserMsg is sent when the user is fetched again
+type fetchUserMsg struct {
+	user    *github.User
+	err     error
+	request int
+}
+
+// fetchAvatarMsg is sent when the avatar is downloaded and rendered
+type fetchAvatarMsg struct {
+	url    string
+	avatar string
+	err    error
+}
+
+// fetchImageMsg is sent when an image of the README is downloaded
+type fetchImageMsg struct {
+	url string
+	img image.Image
+	err error
+}
+
+// maxImageDownloads is the number of README images downloaded at the same time
+const maxImageDownloads = 4
+
+// imageDownloads limits the README images downloaded at the same time
+var imageDownloads = make(chan struct{}, maxImageDownloads)
+
+const (
+	// avatarCols and avatarRows are the size of the avatar in terminal cells, about
+	// square in most fonts
+	avatarCols = 20
+	avatarRows = 10
+
+	// orgAvatarCols and orgAvatarRows are the size of organization avatars
+	orgAvatarCols = 4
+	orgAvatarRows = 2
+)
+
+// copiedMsg is sent when a link is copied to the clipboard
+type copiedMsg struct {
+	text string
+	err  error
+}
+
+// noticeExpiredMsg is sent when a notice has been shown long enough
+type noticeExpiredMsg struct {
+	id int
+}
+
+// noticeDuration is how long notices are shown in the tab bar
+const noticeDuration = 3 * time.Second
+
+// watchMsg is sent at every watch interval to refresh everything
+type watchMsg struct{}
+
+// clockMsg is sent periodically to update the time since the last update
+type clockMsg struct{}
+
+// clockInterval is how often the time since the last update is redrawn
+const clockInterval = 30 * time.Second
+
+// resizeSettledMsg is sent once the terminal has not been resized for a while
+type resizeSettledMsg struct {
+	id int
+}
+
+// resizeDebounce is how long resizing has to stop before the README is rendered
+// for the new width
+const resizeDebounce = 150 * time.Millisecond
+
+// tabState holds the loading state of a tab
+type tabState struct {
+	loading       bool
+	loaded        bool
+	refreshing    bool // Whether the latest fetch was requested by a refresh
+	err           error
+	hasNextPage   bool
+	forksExcluded bool      // Whether the loaded repositories were fetched without forks
+	request       int       // Number of the latest fetch; responses to older fetches are ignored
+	started       time.Time // When the latest fetch started
+	updated       time.Time // When the shown data was fetched
 }
 
 // tabSelectedMsg is sent when a tab is selected
@@ -38,79 +143,395 @@ type tabSelectedMsg struct {
 
 // Model represents the main application UI model
 type Model struct {
-	user              *github.User
-	pinnedRepos       []github.Repository
-	owningRepos       []github.Repository
-	contributedRepos  []github.Repository
-	tabs              components.Tabs
-	repoList          components.RepositoryList
-	userInfo          components.UserInfo
-	viewport          viewport.Model
-	ready             bool
-	width             int
-	height            int
-	loading           bool
-	error             error
-	pinnedLoaded      bool
-	owningLoaded      bool
-	contributedLoaded bool
-	currentTabIndex   int
-}
-
-// Start initializes and starts the TUI application
-func Start(user *github.User) error {
-	m := New(user)
+	login          string
+	profileLoading bool
+	profileStarted time.Time
+	err            error    // Error that ended the application
+	user           tabState // State of the Info tab after the profile is loaded
+	tabs           components.Tabs
+	repoLists      map[string]*components.RepositoryList // One list per repository tab
+	states         map[string]*tabState
+	facets         components.Facets
+	userInfo       components.UserInfo
+	viewport       viewport.Model
+	ready          bool
+	layout         layout
+	splitWidth     int  // Width from which the profile is shown next to the tab content
+	focusProfile   bool // Whether keys scroll the profile instead of the tab content
+	contents       components.Contents
+	contentsOpen   bool // Whether the table of contents of the README is shown
+	search         textinput.Model
+	searching      bool // Whether the search query is typed
+	resizeID       int  // Number of the latest resize; earlier settled messages are ignored
+	width          int
+	height         int
+	tabNames       []string
+	pageSize       config.PageSize
+	prefetch       bool
+	watch          time.Duration
+	avatar         termimage.Protocol // Protocol drawing the avatar, or none
+	released       string             // Escape sequences deleting replaced images, written with the view
+	theme          theme.Theme
+	styles         styles
+	keys           keyMap
+	help           help.Model
+	showHelp       bool
+	notice         string // Message shown in place of the update status
+	noticeID       int    // Number of the latest notice; earlier expirations are ignored
+	spinner        spinner.Model
+}
+
+// Start initializes and starts the TUI application for the given user. It returns
+// the error when the user's profile cannot be fetched.
+func Start(login string, cfg config.Config) error {
+	m := New(login, cfg)
 	p := tea.NewProgram(m, tea.WithAltScreen())
-	_, err := p.Run()
-	return err
+	final, err := p.Run()
+	if err != nil {
+		return err
+	}
+	return final.(Model).err
 }
 
-// New creates a new Model instance
-func New(user *github.User) Model {
-	tabs := components.NewTabs([]string{"Info", "Pinned", "Owning", "Contributed"})
-	repoList := components.NewRepositoryList(nil, "pinned")
-	userInfo := components.NewUserInfo(user, components.NewDefaultRenderer())
+// New creates a new Model instance. The profile is fetched when the application
+// starts, together with the first page of each repository tab when prefetching.
+func New(login string, cfg config.Config) Model {
+	titles := make([]string, len(cfg.Tabs))
+	defaultTab := 0
+	states := make(map[string]*tabState)
+	for i, name := range cfg.Tabs {
+		titles[i] = tabTitles[name]
+		if name == cfg.DefaultTab {
+			defaultTab = i
+		}
+		if name != config.TabInfo {
+			// Tabs not fetched with the profile are loaded when they are shown
+			states[name] = &tabState{loading: cfg.Prefetch || name == config.TabPinned}
+		}
+	}
+
+	th := newTheme(cfg)
+	tabs := components.NewTabs(titles, th)
+	tabs.Select(defaultTab)
 
-	return Model{
-		user:         user,
-		tabs:         tabs,
-		repoList:     repoList,
-		userInfo:     userInfo,
-		ready:        false,
-		loading:      false,
-		error:        nil,
-		pinnedLoaded: false,
-		owningLoaded: false,
+	// The auto style renders READMEs to match the active theme
+	style := cfg.Renderer.Style
+	if style == "auto" {
+		style = th.Glamour
 	}
+	renderer := components.NewRenderer(components.RendererOptions{
+		Style:         style,
+		CodeTheme:     cfg.Renderer.CodeTheme,
+		WrapWidth:     cfg.Renderer.WrapWidth,
+		Emoji:         cfg.Renderer.Emoji,
+		LinkFootnotes: cfg.Renderer.LinkFootnotes,
+	})
+	userInfo := components.NewUserInfo(nil, renderer, th)
+	userInfo.SetHyperlinks(supportsHyperlinks(os.Getenv))
+	// README images are drawn like the avatar. Both scroll with the profile, which
+	// images painted at the cursor do not follow.
+	protocol := termimage.Resolve(termimage.Protocol(cfg.Avatar))
+	if !protocol.Scrolls() {
+		protocol = termimage.Halfblocks
+	}
+	if cfg.READMEImages {
+		userInfo.SetImageProtocol(protocol)
+	}
+
+	keys := newKeyMap(cfg.Keys)
+
+	h := help.New()
+	h.Styles.ShortKey = lipgloss.NewStyle().Foreground(th.Subtle)
+	h.Styles.ShortDesc = lipgloss.NewStyle().Foreground(th.Muted)
+	h.Styles.ShortSeparator = lipgloss.NewStyle().Foreground(th.Muted)
+	h.Styles.FullKey = lipgloss.NewStyle().Foreground(th.Accent)
+	h.Styles.FullDesc = lipgloss.NewStyle().Foreground(th.Subtle)
+	h.Styles.FullSeparator = lipgloss.NewStyle().Foreground(th.Muted)
+
+	search := textinput.New()
+	search.Prompt = "Search: "
+	search.PromptStyle = lipgloss.NewStyle().Foreground(th.Accent)
+
+	m := Model{
+		login:          login,
+		profileLoading: true,
+		profileStarted: time.Now(),
+		tabs:           tabs,
+		repoLists:      make(map[string]*components.RepositoryList),
+		states:         states,
+		facets: components.Facets{
+			HideForks:     cfg.Facets.HideForks,
+			HideArchived:  cfg.Facets.HideArchived,
+			HideTemplates: cfg.Facets.HideTemplates,
+			HideMirrors:   cfg.Facets.HideMirrors,
+		},
+		userInfo:   userInfo,
+		search:     search,
+		ready:      false,
+		tabNames:   cfg.Tabs,
+		pageSize:   cfg.PageSize,
+		prefetch:   cfg.Prefetch,
+		watch:      cfg.Watch,
+		splitWidth: cfg.SplitWidth,
+		avatar:     protocol,
+		theme:      th,
+		styles:     newStyles(th),
+		keys:       keys,
+		help:       h,
+		spinner: spinner.New(
+			spinner.WithSpinner(spinner.MiniDot),
+			spinner.WithStyle(lipgloss.NewStyle().Foreground(th.Accent)),
+		),
+	}
+
+	// Create the lists up front so that they show skeleton rows while loading
+	for tab := range states {
+		m.repoLists[tab] = m.newRepositoryList(nil, tab)
+	}
+
+	return m
 }
 
 // Init initializes the Model
 func (m Model) Init() tea.Cmd {
-	return nil
+	cmds := []tea.Cmd{m.fetchProfile(), m.spinner.Tick, clock()}
+	if m.currentTab() != config.TabInfo {
+		cmds = append(cmds, func() tea.Msg {
+			return tabSelectedMsg{index: m.tabs.Current}
+		})
+	}
+	if m.watch > 0 {
+		cmds = append(cmds, m.watchTick())
+	}
+	return tea.Batch(cmds...)
+}
+
+// currentTab returns the name of the selected tab
+func (m Model) currentTab() string {
+	return m.tabNames[m.tabs.Current]
+}
+
+// newRepositoryList creates a repository list using the model's theme and key bindings
+func (m Model) newRepositoryList(repositories []github.Repository, listType string) *components.RepositoryList {
+	repoList := components.NewRepositoryList(repositories, listType, m.theme)
+	repoList.SetKeyMap(m.keys.repositoryListKeyMap())
+	repoList.SetFacets(m.facets)
+	repoList.SetSize(m.layout.contentWidth, m.layout.height)
+	return &repoList
+}
+
+// currentList returns the list of the selected tab, or nil on the Info tab
+func (m Model) currentList() *components.RepositoryList {
+	return m.repoLists[m.currentTab()]
+}
+
+// fetchProfile fetches the profile and the first page of each repository tab shown,
+// of the pinned tab only when prefetching is disabled. The repository tabs fetched
+// are marked as loading when the model is created.
+func (m Model) fetchProfile() tea.Cmd {
+	login := m.login
+	_, pinned := m.states[config.TabPinned]
+	_, owning := m.states[config.TabOwning]
+	_, contributed := m.states[config.TabContributed]
+	opts := github.ProfileOptions{
+		Owning: github.PageOptions{
+			First:        m.pageSize.Owning,
+			ExcludeForks: m.facets.HideForks,
+		},
+		Contributed: github.PageOptions{
+			First: m.pageSize.Contributed,
+		},
+		SkipPinned:      !pinned,
+		SkipOwning:      !owning || !m.prefetch,
+		SkipContributed: !contributed || !m.prefetch,
+	}
+
+	return func() tea.Msg {
+		profile, err := github.FetchProfile(context.Background(), login, opts)
+		return fetchProfileMsg{
+			profile:      profile,
+			err:          err,
+			excludeForks: opts.Owning.ExcludeForks,
+		}
+	}
+}
+
+// setRepositories shows the fetched repositories in the tab's list. Tabs that are
+// not shown are ignored.
+func (m *Model) setRepositories(tab string, repositories []github.Repository, pageInfo github.PageInfo, excludeForks bool) tea.Cmd {
+	state, ok := m.states[tab]
+	if !ok {
+		return nil
+	}
+	state.loading = false
+	state.loaded = true
+	state.updated = time.Now()
+	state.hasNextPage = pageInfo.HasNextPage
+	state.forksExcluded = excludeForks
+
+	// Keep the cursor, filter and sort order of a reloaded list
+	if state.refreshing {
+		return m.repoLists[tab].RefreshRepositories(repositories)
+	}
+	return m.repoLists[tab].SetRepositories(repositories)
+}
+
+// listOptions returns the sort order and facets of the tab's list, or the defaults
+// when the tab has not been loaded yet
+func (m Model) listOptions(tab string) (github.SortField, components.Facets) {
+	if repoList, ok := m.repoLists[tab]; ok {
+		return repoList.Sort(), repoList.Facets()
+	}
+	return "", m.facets
+}
+
+// startFetch marks the tab as loading and fetches its repositories. A fetch started
+// while another one is in flight supersedes it. Refreshes skip cached responses.
+func (m Model) startFetch(tab string, refresh bool) tea.Cmd {
+	state := m.states[tab]
+	state.loading = true
+	state.refreshing = refresh
+	state.err = nil
+	state.request++
+	state.started = time.Now()
+	return tea.Batch(m.fetchRepositories(tab, state.request, refresh), m.spinner.Tick)
 }
 
-// fetchRepositories fetches repositories based on the tab index
-func fetchRepositories(username string, tabIndex int) tea.Cmd {
+// loadTab fetches the repositories of a tab that has not been loaded yet. Tabs
+// selected while the profile is loading are loaded once it is shown.
+func (m Model) loadTab(tab string) tea.Cmd {
+	state, ok := m.states[tab]
+	if !ok || m.profileLoading || state.loaded || state.loading {
+		return nil
+	}
+	return m.startFetch(tab, false)
+}
+
+// fetchAvatars downloads the avatars of the user and their organizations. The
+// downloads run concurrently.
+func (m Model) fetchAvatars(user *github.User) tea.Cmd {
+	cmds := []tea.Cmd{m.fetchAvatar(user.AvatarURL, avatarCols, avatarRows)}
+	for _, org := range user.Organizations {
+		cmds = append(cmds, m.fetchAvatar(org.AvatarURL, orgAvatarCols, orgAvatarRows))
+	}
+	return tea.Batch(cmds...)
+}
+
+// fetchAvatar downloads the avatar at the URL and renders it in cols by rows cells,
+// unless avatars are disabled or it is shown already
+func (m Model) fetchAvatar(url string, cols, rows int) tea.Cmd {
+	if m.avatar == termimage.None || url == "" || m.userInfo.HasAvatar(url) {
+		return nil
+	}
+
+	protocol := m.avatar
+	return func() tea.Msg {
+		img, err := github.FetchAvatar(context.Background(), url)
+		if err != nil {
+			return fetchAvatarMsg{url: url, err: err}
+		}
+		avatar, err := termimage.Render(img, protocol, cols, rows)
+		return fetchAvatarMsg{url: url, avatar: avatar, err: err}
+	}
+}
+
+// fetchREADMEImages downloads the images of the README not downloaded yet. A few
+// downloads run concurrently.
+func (m Model) fetchREADMEImages() tea.Cmd {
+	urls := m.userInfo.READMEImages()
+	cmds := make([]tea.Cmd, len(urls))
+	for i, url := range urls {
+		cmds[i] = func() tea.Msg {
+			imageDownloads <- struct{}{}
+			defer func() { <-imageDownloads }()
+
+			img, err := github.FetchImage(context.Background(), url)
+			return fetchImageMsg{url: url, img: img, err: err}
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// refresh fetches the data of the tab again, the user for the Info tab and the
+// repositories otherwise
+func (m *Model) refresh(tab string) tea.Cmd {
+	if m.profileLoading {
+		return nil
+	}
+	if _, ok := m.states[tab]; ok {
+		return m.startFetch(tab, true)
+	}
+
+	m.user.loading = true
+	m.user.err = nil
+	m.user.request++
+	m.user.started = time.Now()
+	login, request := m.login, m.user.request
+	return tea.Batch(func() tea.Msg {
+		user, err := github.FetchUser(github.WithRefresh(context.Background()), login)
+		return fetchUserMsg{user: user, err: err, request: request}
+	}, m.spinner.Tick)
+}
+
+// refreshAll fetches the data of every tab again
+func (m *Model) refreshAll() tea.Cmd {
+	cmds := make([]tea.Cmd, 0, len(m.tabNames))
+	for _, tab := range m.tabNames {
+		cmds = append(cmds, m.refresh(tab))
+	}
+	return tea.Batch(cmds...)
+}
+
+// watchTick waits for the watch interval
+func (m Model) watchTick() tea.Cmd {
+	return tea.Tick(m.watch, func(time.Time) tea.Msg {
+		return watchMsg{}
+	})
+}
+
+// clock waits until the time since the last update is redrawn
+func clock() tea.Cmd {
+	return tea.Tick(clockInterval, func(time.Time) tea.Msg {
+		return clockMsg{}
+	})
+}
+
+// fetchRepositories fetches repositories for the tab, in the order of its list.
+// Forks hidden from the Owning tab are left out by the API so that they do not
+// take up the page.
+func (m Model) fetchRepositories(tab string, request int, refresh bool) tea.Cmd {
+	username := m.login
+	pageSize := m.pageSize
+	sort, facets := m.listOptions(tab)
+	excludeForks := tab == config.TabOwning && facets.HideForks
+
 	return func() tea.Msg {
 		ctx := context.Background()
+		if refresh {
+			ctx = github.WithRefresh(ctx)
+		}
 		var (
-			repos []github.Repository
-			err   error
+			repos    []github.Repository
+			pageInfo github.PageInfo
+			err      error
 		)
 
-		switch tabIndex {
-		case 1: // Pinned
+		switch tab {
+		case config.TabPinned:
 			repos, err = github.FetchPinnedRepositories(ctx, username)
-		case 2: // Owning
-			repos, err = github.FetchOwningRepositories(ctx, username)
-		case 3: // Contributed
-			repos, err = github.FetchContributedRepositories(ctx, username)
+		case config.TabOwning:
+			repos, pageInfo, err = github.FetchOwningRepositoriesPage(ctx, username, github.PageOptions{First: pageSize.Owning, Sort: sort, ExcludeForks: excludeForks})
+		case config.TabContributed:
+			repos, pageInfo, err = github.FetchContributedRepositoriesPage(ctx, username, github.PageOptions{First: pageSize.Contributed, Sort: sort})
 		}
 
 		return fetchRepositoriesMsg{
 			repositories: repos,
-			err:         err,
-			tabIndex:    tabIndex,
+			pageInfo:     pageInfo,
+			err:          err,
+			tab:          tab,
+			request:      request,
+			excludeForks: excludeForks,
 		}
 	}
 }
@@ -122,27 +543,97 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds []tea.Cmd
 	)
 
+	m.updateKeyStates()
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c", "esc":
+		if repoList := m.currentList(); repoList != nil && repoList.CapturesKey(msg) {
+			break
+		}
+
+		if m.showHelp {
+			// Any quit or help key closes the help overlay, except ctrl+c
+			if msg.String() == "ctrl+c" {
+				return m, tea.Quit
+			}
+			if key.Matches(msg, m.keys.Help, m.keys.Quit) {
+				m.showHelp = false
+			}
+			return m, nil
+		}
+
+		if m.contentsOpen {
+			if msg.String() == "ctrl+c" {
+				return m, tea.Quit
+			}
+			m.updateContents(msg)
+			return m, nil
+		}
+
+		if m.searching {
+			if msg.String() == "ctrl+c" {
+				return m, tea.Quit
+			}
+			return m, m.updateSearch(msg)
+		}
+
+		switch {
+		case msg.Type == tea.KeyEsc && m.profileFocused() && m.userInfo.SearchQuery() != "":
+			// Esc clears the search before quitting
+			m.userInfo.SetSearch("")
+			m.updateProfile()
+			return m, nil
+		case key.Matches(msg, m.keys.Quit):
 			return m, tea.Quit
-		case "right", "l":
+		case key.Matches(msg, m.keys.Help):
+			m.showHelp = true
+			return m, nil
+		case key.Matches(msg, m.keys.NextTab):
 			m.tabs.Next()
 			return m, func() tea.Msg {
 				return tabSelectedMsg{index: m.tabs.Current}
 			}
-		case "left", "h":
+		case key.Matches(msg, m.keys.PrevTab):
 			m.tabs.Prev()
 			return m, func() tea.Msg {
 				return tabSelectedMsg{index: m.tabs.Current}
 			}
-		case "r":
-			if m.error != nil {
-				m.loading = true
-				m.error = nil
-				cmd = fetchRepositories(m.user.Login, m.currentTabIndex)
-				cmds = append(cmds, cmd)
+		case key.Matches(msg, m.keys.Refresh):
+			cmds = append(cmds, m.refresh(m.currentTab()))
+		case key.Matches(msg, m.keys.RefreshAll):
+			cmds = append(cmds, m.refreshAll())
+		case key.Matches(msg, m.keys.Focus):
+			m.focusProfile = !m.focusProfile
+			return m, nil
+		case key.Matches(msg, m.keys.NextLink):
+			m.userInfo.NextLink()
+			m.scrollToLink()
+		case key.Matches(msg, m.keys.PrevLink):
+			m.userInfo.PrevLink()
+			m.scrollToLink()
+		case key.Matches(msg, m.keys.Copy):
+			if link, ok := m.userInfo.SelectedLink(); ok {
+				cmds = append(cmds, copyToClipboard(link.URL))
+			}
+		case key.Matches(msg, m.keys.Search):
+			m.searching = true
+			m.search.SetValue("")
+			m.userInfo.SetSearch("")
+			m.updateProfile()
+			return m, m.search.Focus()
+		case key.Matches(msg, m.keys.NextMatch):
+			m.userInfo.NextMatch()
+			m.scrollToMatch()
+		case key.Matches(msg, m.keys.PrevMatch):
+			m.userInfo.PrevMatch()
+			m.scrollToMatch()
+		case key.Matches(msg, m.keys.Contents):
+			m.contents = components.NewContents(m.userInfo.Headings(), m.theme)
+			m.contentsOpen = true
+			return m, nil
+		case m.profileFocused() && key.Matches(msg, m.keys.Open):
+			if link, ok := m.userInfo.SelectedLink(); ok {
+				cmds = append(cmds, openURL(link.URL))
 			}
 		}
 
@@ -151,15 +642,35 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 
 		if !m.ready {
-			m.viewport = viewport.New(msg.Width, msg.Height-4) // 4 for tabs and help
+			m.viewport = viewport.New(0, 0)
 			m.viewport.YPosition = 0
+			m.viewport.KeyMap.Up = m.keys.Up
+			m.viewport.KeyMap.Down = m.keys.Down
 			m.ready = true
+			cmds = append(cmds, m.updateLayout())
+			m.userInfo.SetWidth(m.layout.profileWidth)
+			cmds = append(cmds, m.userInfo.RenderREADME(), m.userInfo.DrawREADMEImages())
 		} else {
-			m.viewport.Width = msg.Width
-			m.viewport.Height = msg.Height - 4
+			cmds = append(cmds, m.updateLayout())
+			// Render the README again only once resizing stops
+			m.resizeID++
+			id := m.resizeID
+			cmds = append(cmds, tea.Tick(resizeDebounce, func(time.Time) tea.Msg {
+				return resizeSettledMsg{id: id}
+			}))
+		}
+
+	case resizeSettledMsg:
+		if msg.id == m.resizeID {
+			m.userInfo.SetWidth(m.layout.profileWidth)
+			cmds = append(cmds, m.userInfo.RenderREADME(), m.userInfo.DrawREADMEImages())
 		}
-		m.repoList.SetSize(msg.Width, msg.Height-4)
-		m.userInfo.SetWidth(msg.Width)
+
+	case components.READMERenderedMsg:
+		m.userInfo.SetREADME(msg)
+
+	case components.READMEImageDrawnMsg:
+		m.userInfo.SetDrawnREADMEImage(msg)
 
 	case components.RepositorySelectedMsg:
 		if msg.Repository != nil {
@@ -167,132 +678,534 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, cmd)
 		}
 
+	case fetchProfileMsg:
+		m.profileLoading = false
+		if msg.err != nil {
+			m.err = msg.err
+			return m, tea.Quit
+		}
+
+		m.userInfo.SetUser(msg.profile.User)
+		cmds = append(cmds, m.userInfo.RenderREADME(), m.fetchAvatars(msg.profile.User), m.fetchREADMEImages())
+		m.user.loaded = true
+		m.user.updated = time.Now()
+		cmds = append(cmds, m.setRepositories(config.TabPinned, msg.profile.Pinned, github.PageInfo{}, false))
+		if m.prefetch {
+			cmds = append(cmds,
+				m.setRepositories(config.TabOwning, msg.profile.Owning, msg.profile.OwningPageInfo, msg.excludeForks),
+				m.setRepositories(config.TabContributed, msg.profile.Contributed, msg.profile.ContributedPageInfo, false),
+			)
+		} else {
+			cmds = append(cmds, m.loadTab(m.currentTab()))
+		}
+
+	case spinner.TickMsg:
+		// Stop ticking once nothing is loading
+		if m.isLoading() {
+			m.spinner, cmd = m.spinner.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+
+	case fetchUserMsg:
+		if msg.request != m.user.request {
+			break
+		}
+		m.user.loading = false
+		if msg.err != nil {
+			m.user.err = msg.err
+			break
+		}
+		m.user.updated = time.Now()
+		m.userInfo.SetUser(msg.user)
+		cmds = append(cmds, m.userInfo.RenderREADME(), m.fetchAvatars(msg.user), m.fetchREADMEImages())
+
+	case fetchAvatarMsg:
+		// The avatar is left out when it cannot be shown
+		if msg.err == nil {
+			m.userInfo.SetAvatar(msg.url, msg.avatar)
+		}
+
+	case fetchImageMsg:
+		// Images are drawn in the background like the README
+		cmds = append(cmds, m.userInfo.SetREADMEImage(msg.url, msg.img, msg.err))
+
+	case copiedMsg:
+		notice := "Copied " + msg.text
+		if msg.err != nil {
+			notice = "Copy failed: " + msg.err.Error()
+		}
+		cmds = append(cmds, m.showNotice(notice))
+
+	case noticeExpiredMsg:
+		if msg.id == m.noticeID {
+			m.notice = ""
+		}
+
+	case watchMsg:
+		cmds = append(cmds, m.refreshAll(), m.watchTick())
+
+	case clockMsg:
+		cmds = append(cmds, clock())
+
+	case components.HighlightExpiredMsg:
+		// Expire the highlight of lists that are not shown as well
+		if msg.ListType != m.currentTab() {
+			_, cmd = m.repoLists[msg.ListType].Update(msg)
+			cmds = append(cmds, cmd)
+		}
+
 	case fetchRepositoriesMsg:
-		m.loading = false
-		m.currentTabIndex = msg.tabIndex
+		state := m.states[msg.tab]
+		if m.profileLoading || msg.request != state.request {
+			// Superseded by a later fetch of the same tab
+			break
+		}
+		state.loading = false
 		if msg.err != nil {
-			m.error = msg.err
-			return m, nil
+			state.err = msg.err
+			break
 		}
+		cmds = append(cmds, m.setRepositories(msg.tab, msg.repositories, msg.pageInfo, msg.excludeForks))
 
-		switch msg.tabIndex {
-		case 1: // Pinned
-			m.pinnedRepos = msg.repositories
-			m.pinnedLoaded = true
-			m.repoList = components.NewRepositoryList(msg.repositories, "pinned")
-		case 2: // Owning
-			m.owningRepos = msg.repositories
-			m.owningLoaded = true
-			m.repoList = components.NewRepositoryList(msg.repositories, "owning")
-		case 3: // Contributed
-			m.contributedRepos = msg.repositories
-			m.contributedLoaded = true
-			m.repoList = components.NewRepositoryList(msg.repositories, "contributed")
+	case components.SortChangedMsg:
+		// Reordering on the client is enough when every repository is loaded already,
+		// otherwise fetch the repositories that come first in the new order
+		if msg.Sort.ServerSide() && m.states[msg.ListType].hasNextPage {
+			cmds = append(cmds, m.startFetch(msg.ListType, false))
 		}
-		m.repoList.SetSize(m.width, m.height-4)
 
-	case tabSelectedMsg:
-		m.currentTabIndex = msg.index
-		switch msg.index {
-		case 1: // Pinned
-			if !m.pinnedLoaded && !m.loading {
-				m.loading = true
-				m.error = nil
-				cmd = fetchRepositories(m.user.Login, msg.index)
-				cmds = append(cmds, cmd)
-			} else if m.pinnedLoaded {
-				m.repoList = components.NewRepositoryList(m.pinnedRepos, "pinned")
-				m.repoList.SetSize(m.width, m.height-4)
-			}
-		case 2: // Owning
-			if !m.owningLoaded && !m.loading {
-				m.loading = true
-				m.error = nil
-				cmd = fetchRepositories(m.user.Login, msg.index)
-				cmds = append(cmds, cmd)
-			} else if m.owningLoaded {
-				m.repoList = components.NewRepositoryList(m.owningRepos, "owning")
-				m.repoList.SetSize(m.width, m.height-4)
-			}
-		case 3: // Contributed
-			if !m.contributedLoaded && !m.loading {
-				m.loading = true
-				m.error = nil
-				cmd = fetchRepositories(m.user.Login, msg.index)
-				cmds = append(cmds, cmd)
-			} else if m.contributedLoaded {
-				m.repoList = components.NewRepositoryList(m.contributedRepos, "contributed")
-				m.repoList.SetSize(m.width, m.height-4)
-			}
+	case components.FacetsChangedMsg:
+		// Refetch the Owning tab when forks were left out by the API but are shown
+		// now, or when they are hidden now and more repositories can fill the page
+		state := m.states[msg.ListType]
+		if msg.ListType == config.TabOwning && msg.Facets.HideForks != state.forksExcluded &&
+			(state.forksExcluded || state.hasNextPage) {
+			cmds = append(cmds, m.startFetch(msg.ListType, false))
 		}
+
+	case tabSelectedMsg:
+		// Loaded lists are kept as they were left
+		cmds = append(cmds, m.loadTab(m.tabNames[msg.index]))
 	}
 
-	if m.tabs.Current > 0 { // Repository tabs
-		newRepoList, cmd := m.repoList.Update(msg)
-		m.repoList = *newRepoList
+	// Next to the profile, keys go to the focused pane only
+	_, isKey := msg.(tea.KeyMsg)
+	tab := m.currentTab()
+	if tab != config.TabInfo && m.states[tab].loaded && !(isKey && m.focusProfile) { // Repository tabs
+		_, cmd = m.repoLists[tab].Update(msg)
 		cmds = append(cmds, cmd)
-	} else {
-		m.viewport.SetContent(m.userInfo.View())
-		m.viewport, cmd = m.viewport.Update(msg)
+	}
+	if (tab == config.TabInfo || m.layout.split) && !m.profileLoading {
+		m.updateProfile()
+		if !isKey || !m.layout.split || m.focusProfile {
+			m.viewport, cmd = m.viewport.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	// Keep the cursor of the search blinking
+	if m.searching && !isKey {
+		m.search, cmd = m.search.Update(msg)
 		cmds = append(cmds, cmd)
 	}
 
+	m.updateLoadingStatus()
+
+	// Images replaced in the profile are deleted with the next frame
+	if released := m.userInfo.TakeReleasedImages(); released != "" {
+		m.released = released
+	}
+
 	return m, tea.Batch(cmds...)
 }
 
+// isLoading reports whether the profile or any repository tab is being fetched
+func (m Model) isLoading() bool {
+	if m.profileLoading || m.user.loading {
+		return true
+	}
+	for _, state := range m.states {
+		if state.loading {
+			return true
+		}
+	}
+	return false
+}
+
+// elapsed returns the whole seconds elapsed since the given time
+func elapsed(started time.Time) time.Duration {
+	return time.Since(started).Truncate(time.Second)
+}
+
+// loadingStatus returns the spinner and the time elapsed since the fetch started
+func (m Model) loadingStatus(started time.Time) string {
+	return fmt.Sprintf("%s %s", m.spinner.View(), elapsed(started))
+}
+
+// updateLoadingStatus shows the loading status of each repository tab in its list
+func (m *Model) updateLoadingStatus() {
+	for tab, repoList := range m.repoLists {
+		state := m.states[tab]
+		switch {
+		case m.profileLoading:
+			repoList.SetLoading(m.loadingStatus(m.profileStarted))
+		case state.loading:
+			repoList.SetLoading(m.loadingStatus(state.started))
+		default:
+			repoList.SetLoading("")
+		}
+	}
+}
+
+// profileFocused reports whether keys go to the loaded profile, on the Info tab or
+// next to the tab content
+func (m Model) profileFocused() bool {
+	if m.profileLoading {
+		return false
+	}
+	return m.currentTab() == config.TabInfo || (m.layout.split && m.focusProfile)
+}
+
+// scrollToLink scrolls the profile so that the selected link is shown
+func (m *Model) scrollToLink() {
+	link, ok := m.userInfo.SelectedLink()
+	if !ok {
+		return
+	}
+	switch {
+	case link.Line < m.viewport.YOffset:
+		m.viewport.SetYOffset(link.Line)
+	case link.Line >= m.viewport.YOffset+m.viewport.Height:
+		m.viewport.SetYOffset(link.Line - m.viewport.Height + 1)
+	}
+}
+
+// updateProfile shows the profile again in the viewport if it changed
+func (m *Model) updateProfile() {
+	if m.userInfo.Dirty() {
+		m.viewport.SetContent(m.userInfo.View())
+		m.contents.SetHeadings(m.userInfo.Headings())
+	}
+}
+
+// updateSearch handles keys while the search query is typed, highlighting its
+// matches as it changes. Enter keeps the matches and Esc clears them.
+func (m *Model) updateSearch(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEnter, tea.KeyEsc:
+		m.searching = false
+		m.search.Blur()
+		if msg.Type == tea.KeyEsc || m.search.Value() == "" {
+			m.userInfo.SetSearch("")
+			m.updateProfile()
+		}
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.search, cmd = m.search.Update(msg)
+	m.userInfo.SetSearch(m.search.Value())
+	m.scrollToMatch()
+	return cmd
+}
+
+// scrollToMatch scrolls the profile so that the current search match is shown
+func (m *Model) scrollToMatch() {
+	m.updateProfile()
+	match, _, ok := m.userInfo.CurrentMatch()
+	if !ok {
+		return
+	}
+	switch {
+	case match.Line < m.viewport.YOffset:
+		m.viewport.SetYOffset(match.Line)
+	case match.Line >= m.viewport.YOffset+m.viewport.Height:
+		m.viewport.SetYOffset(match.Line - m.viewport.Height/2)
+	}
+}
+
+// matchStatus describes the search matches, such as "match 2/5"
+func (m Model) matchStatus() string {
+	_, index, ok := m.userInfo.CurrentMatch()
+	if !ok {
+		return "no matches"
+	}
+	return fmt.Sprintf("match %d/%d", index+1, m.userInfo.MatchCount())
+}
+
+// updateContents handles keys while the table of contents is open, jumping to the
+// selected heading
+func (m *Model) updateContents(msg tea.KeyMsg) {
+	switch {
+	case key.Matches(msg, m.keys.Contents), msg.Type == tea.KeyEsc:
+		m.contentsOpen = false
+	case key.Matches(msg, m.keys.Up):
+		m.contents.MoveCursor(-1)
+	case key.Matches(msg, m.keys.Down):
+		m.contents.MoveCursor(1)
+	case key.Matches(msg, m.keys.Open):
+		if heading, ok := m.contents.Selected(); ok {
+			m.viewport.SetYOffset(heading.Line)
+		}
+		m.contentsOpen = false
+	}
+}
+
+// showNotice shows the message in the tab bar for a while
+func (m *Model) showNotice(notice string) tea.Cmd {
+	m.notice = notice
+	m.noticeID++
+	id := m.noticeID
+	return tea.Tick(noticeDuration, func(time.Time) tea.Msg {
+		return noticeExpiredMsg{id: id}
+	})
+}
+
+// updateKeyStates enables the key bindings that apply to the current state, so that
+// the help only lists keys that do something
+func (m *Model) updateKeyStates() {
+	state, repositoryTab := m.states[m.currentTab()]
+	listShown := repositoryTab && state.loaded && state.err == nil
+	listFocused := listShown && !m.focusProfile
+	_, linkSelected := m.userInfo.SelectedLink()
+	linkFocused := m.profileFocused() && linkSelected
+	m.keys.Open.SetEnabled(listFocused || linkFocused || m.contentsOpen)
+	m.keys.Filter.SetEnabled(listFocused)
+	m.keys.Sort.SetEnabled(listFocused)
+	m.keys.Facets.SetEnabled(listFocused)
+	m.keys.Focus.SetEnabled(m.layout.split)
+	m.keys.NextLink.SetEnabled(m.profileFocused())
+	m.keys.PrevLink.SetEnabled(m.profileFocused())
+	m.keys.Copy.SetEnabled(linkFocused)
+	m.keys.Contents.SetEnabled(m.profileFocused() && len(m.userInfo.Headings()) > 0)
+	m.keys.Search.SetEnabled(m.profileFocused())
+	m.keys.NextMatch.SetEnabled(m.profileFocused() && m.userInfo.SearchQuery() != "")
+	m.keys.PrevMatch.SetEnabled(m.profileFocused() && m.userInfo.SearchQuery() != "")
+	m.keys.Refresh.SetEnabled(!m.profileLoading)
+	m.keys.RefreshAll.SetEnabled(!m.profileLoading)
+}
+
+// updateLayout fits the panes between the header and the footer. The Info tab is
+// hidden while the profile is shown next to the tab content, selecting the next tab
+// when it was selected.
+func (m *Model) updateLayout() tea.Cmd {
+	m.help.Width = m.width
+	m.search.Width = max(1, m.width-lipgloss.Width(m.search.Prompt)-20)
+
+	infoIndex := slices.Index(m.tabNames, config.TabInfo)
+	splitWidth := m.splitWidth
+	if infoIndex < 0 || len(m.states) == 0 {
+		// Nothing to show next to each other
+		splitWidth = 0
+	}
+	m.layout = newLayout(m.width, m.height, m.headerView(), m.footerView(), splitWidth)
+
+	m.viewport.Width = m.layout.profileWidth
+	m.viewport.Height = m.layout.height
+	for _, repoList := range m.repoLists {
+		repoList.SetSize(m.layout.contentWidth, m.layout.height)
+	}
+	if !m.layout.split {
+		m.focusProfile = false
+	}
+	if !m.profileFocused() {
+		m.contentsOpen = false
+	}
+
+	if infoIndex < 0 {
+		return nil
+	}
+	current := m.tabs.Current
+	m.tabs.SetHidden(infoIndex, m.layout.split)
+	if m.tabs.Current == current {
+		return nil
+	}
+	return func() tea.Msg {
+		return tabSelectedMsg{index: m.tabs.Current}
+	}
+}
+
+// tabBarView renders the tabs with the update status of the current tab on the right
+func (m Model) tabBarView() string {
+	tabs := m.tabs.View()
+	status := m.statusView()
+	gap := m.width - lipgloss.Width(tabs) - lipgloss.Width(status)
+	if status == "" || gap < 2 {
+		return tabs
+	}
+	return tabs + strings.Repeat(" ", gap) + status
+}
+
+// statusView describes when the data of the current tab was last updated, unless
+// a notice is shown
+func (m Model) statusView() string {
+	if m.notice != "" {
+		return m.styles.divider.Render(m.notice)
+	}
+	if m.profileFocused() && m.userInfo.SearchQuery() != "" {
+		return m.styles.divider.Render(m.matchStatus())
+	}
+
+	state, ok := m.states[m.currentTab()]
+	if !ok {
+		state = &m.user
+	}
+
+	switch {
+	case !state.loaded:
+		return ""
+	case state.loading:
+		return m.spinner.View() + m.styles.divider.Render(" refreshing")
+	case state.err != nil:
+		return m.styles.error.Render("refresh failed")
+	default:
+		return m.styles.divider.Render("updated " + timeAgo(state.updated))
+	}
+}
+
+// timeAgo describes how long ago the given time was
+func timeAgo(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+// headerView renders the tab bar followed by a blank line
+func (m Model) headerView() string {
+	return m.tabBarView() + "\n"
+}
+
+// footerView renders the short help
+func (m Model) footerView() string {
+	if m.searching {
+		return m.search.View() + "  " + m.styles.divider.Render(m.matchStatus())
+	}
+	return m.help.ShortHelpView(m.keys.ShortHelp())
+}
+
 // View renders the UI
 func (m Model) View() string {
 	if !m.ready {
 		return "\n  Initializing..."
 	}
 
-	var content string
+	m.updateKeyStates()
+
+	header := m.headerView()
+
+	// Help overlay
+	if m.showHelp {
+		return m.released + header + "\n" + m.helpView(m.height-lipgloss.Height(header))
+	}
+
+	content := m.tabView()
+	if m.layout.split {
+		content = lipgloss.JoinHorizontal(lipgloss.Top,
+			lipgloss.NewStyle().Width(m.layout.profileWidth).Render(m.profileView()),
+			m.separatorView(),
+			content,
+		)
+	}
+
+	return m.released + header + "\n" + content + "\n" + m.footerView()
+}
+
+// separatorView renders the line between the profile and the tab content,
+// highlighted while the profile is focused
+func (m Model) separatorView() string {
+	style := m.styles.divider
+	if m.focusProfile {
+		style = m.styles.title
+	}
+	lines := make([]string, max(m.layout.height, 1))
+	for i := range lines {
+		lines[i] = style.Render(paneSeparator)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// profileView renders the profile, or its loading status
+func (m Model) profileView() string {
+	if m.profileLoading {
+		return fmt.Sprintf("  %s Loading %s... %s", m.spinner.View(), m.login, elapsed(m.profileStarted))
+	}
+	if m.contentsOpen {
+		hint := strings.Join([]string{
+			m.keys.Open.Help().Key + " jump",
+			m.keys.Contents.Help().Key + "/esc close",
+		}, " • ")
+		return m.contents.View(m.viewport.Width, m.viewport.Height, hint)
+	}
+	return m.viewport.View()
+}
 
-	// Tabs
-	content += m.tabs.View() + "\n\n"
+// tabView renders the content of the current tab
+func (m Model) tabView() string {
+	var content string
 
-	// Content
-	if m.tabs.Current > 0 { // Repository tabs
-		if m.loading {
-			content += "Loading..."
-		} else if m.error != nil {
-			errMsg := m.error.Error()
+	if state, ok := m.states[m.currentTab()]; ok { // Repository tabs
+		if state.err != nil {
+			errMsg := state.err.Error()
 			if strings.Contains(errMsg, "Could not resolve") {
-				content += errorStyle.Render("Authentication error") + "\n"
-				content += errorHelpStyle.Render("Please run 'gh auth login' to authenticate with GitHub")
+				content += m.styles.error.Render("Authentication error") + "\n"
+				content += m.styles.errorHelp.Render("Please run 'gh auth login' to authenticate with GitHub")
 			} else if strings.Contains(errMsg, "connect:") || strings.Contains(errMsg, "timeout") {
-				content += errorStyle.Render("Network error") + "\n"
-				content += errorHelpStyle.Render("Please check your internet connection")
+				content += m.styles.error.Render("Network error") + "\n"
+				content += m.styles.errorHelp.Render("Please check your internet connection")
 			} else {
-				content += errorStyle.Render("Error: "+errMsg) + "\n"
-				content += errorHelpStyle.Render("An unexpected error occurred")
+				content += m.styles.error.Render("Error: "+errMsg) + "\n"
+				content += m.styles.errorHelp.Render("An unexpected error occurred")
 			}
-			content += "\n\n" + errorHelpStyle.Render("Press r to retry")
+			content += "\n\n" + m.styles.errorHelp.Render("Press "+m.keys.Refresh.Help().Key+" to retry")
 		} else {
-			content += m.repoList.View()
+			content += m.currentList().View()
 		}
 	} else {
-		content += m.viewport.View()
+		content += m.profileView()
 	}
 
-	// Help
-	var help string
-	if m.tabs.Current > 0 {
-		if m.error != nil {
-			help = "r: Retry • ←/→: Switch tabs • q: Quit"
-		} else {
-			help = "↑/↓: Navigate • enter: Open in browser • q: Quit"
+	return content
+}
+
+// helpView renders the help overlay in the given height
+func (m Model) helpView(height int) string {
+	h := m.help
+	h.ShowAll = true
+
+	body := m.styles.title.Render("Key bindings") + "\n\n" +
+		h.View(m.keys) + "\n\n" +
+		m.styles.divider.Render("Press "+m.keys.Help.Help().Key+" to close")
+
+	return lipgloss.Place(m.width, height, lipgloss.Center, lipgloss.Center, body)
+}
+
+// copyToClipboard copies the text to the system clipboard, falling back to the
+// OSC 52 escape sequence that asks the terminal to do it
+func copyToClipboard(text string) tea.Cmd {
+	return func() tea.Msg {
+		if err := clipboard.WriteAll(text); err != nil {
+			if _, err := osc52.New(text).WriteTo(os.Stderr); err != nil {
+				return copiedMsg{text: text, err: err}
+			}
 		}
-	} else {
-		help = "←/→: Switch tabs • q: Quit"
+		return copiedMsg{text: text}
 	}
-	content += "\n" + dividerStyle.Render(help)
-
-	return content
 }
 
-// openURL opens the given URL in the default browser
+// openURL opens the given URL in the default browser, unless it is not a web or
+// email link
 func openURL(url string) tea.Cmd {
+	if !components.IsOpenableURL(url) {
+		return nil
+	}
 	return func() tea.Msg {
 		b := browser.New("", os.Stdout, os.Stdin)
 		_ = b.Browse(url)
@@ -0,0 +1,357 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tnagatomi/gh-portrait/internal/config"
+	"github.com/tnagatomi/gh-portrait/internal/github"
+	"github.com/tnagatomi/gh-portrait/internal/ui/components"
+)
+
+// newTestModel creates a model sized for an 80x40 terminal that has loaded
+// octocat's profile without repositories
+func newTestModel(t *testing.T, cfg config.Config) Model {
+	t.Helper()
+	m := New("octocat", cfg)
+	return update(t, m,
+		tea.WindowSizeMsg{Width: 80, Height: 40},
+		fetchProfileMsg{profile: &github.Profile{User: &github.User{Login: "octocat"}}},
+	)
+}
+
+// update sends the message to the model, leaving the returned commands unrun
+func update(t *testing.T, m Model, msgs ...tea.Msg) Model {
+	t.Helper()
+	for _, msg := range msgs {
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+	return m
+}
+
+// selectTab selects the tab at the index as the tab keys do
+func selectTab(t *testing.T, m Model, index int) Model {
+	t.Helper()
+	m.tabs.Select(index)
+	return update(t, m, tabSelectedMsg{index: index})
+}
+
+// fetched returns the response to the latest fetch of the tab
+func fetched(m Model, tab string, repos []github.Repository) fetchRepositoriesMsg {
+	return fetchRepositoriesMsg{repositories: repos, tab: tab, request: m.states[tab].request}
+}
+
+// testRepositories returns n repositories named repo-0 to repo-<n-1>
+func testRepositories(n int) []github.Repository {
+	repos := make([]github.Repository, n)
+	for i := range repos {
+		repos[i] = github.Repository{Owner: "octocat", Name: fmt.Sprintf("repo-%d", i)}
+	}
+	return repos
+}
+
+func TestModelKeepsListStatePerTab(t *testing.T) {
+	m := newTestModel(t, config.Default())
+
+	owning := slices.Index(m.tabNames, config.TabOwning)
+	pinned := slices.Index(m.tabNames, config.TabPinned)
+
+	m = selectTab(t, m, owning)
+	m = update(t, m,
+		fetched(m, config.TabOwning, testRepositories(10)),
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")},
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("re")},
+		tea.KeyMsg{Type: tea.KeyEnter},
+		tea.KeyMsg{Type: tea.KeyDown},
+		tea.KeyMsg{Type: tea.KeyDown},
+	)
+
+	m = selectTab(t, m, pinned)
+	m = update(t, m, fetched(m, config.TabPinned, testRepositories(3)))
+	if got := m.currentList().FilterValue(); got != "" {
+		t.Errorf("pinned FilterValue() = %q, want empty", got)
+	}
+
+	m = selectTab(t, m, owning)
+	if got := m.currentList().FilterValue(); got != "re" {
+		t.Errorf("FilterValue() = %q, want %q", got, "re")
+	}
+	if got := m.currentList().Index(); got != 2 {
+		t.Errorf("Index() = %v, want %v", got, 2)
+	}
+}
+
+func TestModelLoadsTabsWhenShownWithoutPrefetch(t *testing.T) {
+	tests := []struct {
+		name        string
+		prefetch    bool
+		defaultTab  string
+		wantLoading []string
+		wantLoaded  []string
+	}{
+		{
+			name:       "prefetch",
+			prefetch:   true,
+			defaultTab: config.TabInfo,
+			wantLoaded: []string{config.TabPinned, config.TabOwning, config.TabContributed},
+		},
+		{
+			name:       "no prefetch",
+			defaultTab: config.TabInfo,
+			wantLoaded: []string{config.TabPinned},
+		},
+		{
+			name:        "no prefetch with a repository tab shown first",
+			defaultTab:  config.TabContributed,
+			wantLoading: []string{config.TabContributed},
+			wantLoaded:  []string{config.TabPinned},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Prefetch = tt.prefetch
+			cfg.DefaultTab = tt.defaultTab
+			m := newTestModel(t, cfg)
+
+			for tab, state := range m.states {
+				if got, want := state.loading, slices.Contains(tt.wantLoading, tab); got != want {
+					t.Errorf("%s loading = %v, want %v", tab, got, want)
+				}
+				if got, want := state.loaded, slices.Contains(tt.wantLoaded, tab); got != want {
+					t.Errorf("%s loaded = %v, want %v", tab, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestModelLoadsTabWhenSelectedWithoutPrefetch(t *testing.T) {
+	cfg := config.Default()
+	cfg.Prefetch = false
+	m := newTestModel(t, cfg)
+
+	m = selectTab(t, m, slices.Index(m.tabNames, config.TabOwning))
+	if !m.states[config.TabOwning].loading {
+		t.Errorf("owning loading = false after selecting the tab, want true")
+	}
+	m = update(t, m, fetched(m, config.TabOwning, testRepositories(2)))
+	if state := m.states[config.TabOwning]; state.loading || !state.loaded {
+		t.Errorf("owning loading = %v, loaded = %v after the fetch, want false, true", state.loading, state.loaded)
+	}
+}
+
+// listed reports whether the list of the tab shows the repository
+func listed(m Model, tab, name string) bool {
+	return strings.Contains(m.repoLists[tab].View(), name)
+}
+
+func TestModelAppliesFetchesToTheirTab(t *testing.T) {
+	repos := []github.Repository{{Owner: "octocat", Name: "fetched-repo"}}
+
+	tests := []struct {
+		name        string
+		fetch       func(m Model) fetchRepositoriesMsg
+		wantListed  []string
+		wantLoading []string
+	}{
+		{
+			name: "latest fetch",
+			fetch: func(m Model) fetchRepositoriesMsg {
+				m.startFetch(config.TabOwning, false)
+				return fetched(m, config.TabOwning, repos)
+			},
+			wantListed: []string{config.TabOwning},
+		},
+		{
+			name: "superseded fetch",
+			fetch: func(m Model) fetchRepositoriesMsg {
+				m.startFetch(config.TabOwning, false)
+				msg := fetched(m, config.TabOwning, repos)
+				m.startFetch(config.TabOwning, false)
+				return msg
+			},
+			wantLoading: []string{config.TabOwning},
+		},
+		{
+			name: "fetch of another tab",
+			fetch: func(m Model) fetchRepositoriesMsg {
+				m.startFetch(config.TabOwning, false)
+				m.startFetch(config.TabContributed, false)
+				return fetched(m, config.TabContributed, repos)
+			},
+			wantListed:  []string{config.TabContributed},
+			wantLoading: []string{config.TabOwning},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, config.Default())
+			m = update(t, m, tt.fetch(m))
+
+			for tab, state := range m.states {
+				if got, want := listed(m, tab, "fetched-repo"), slices.Contains(tt.wantListed, tab); got != want {
+					t.Errorf("%s listed = %v, want %v", tab, got, want)
+				}
+				if got, want := state.loading, slices.Contains(tt.wantLoading, tab); got != want {
+					t.Errorf("%s loading = %v, want %v", tab, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestModelAppliesProfileToEachTab(t *testing.T) {
+	m := New("octocat", config.Default())
+	m = update(t, m,
+		tea.WindowSizeMsg{Width: 80, Height: 40},
+		fetched(m, config.TabOwning, []github.Repository{{Owner: "octocat", Name: "early-repo"}}),
+	)
+	// Fetches answered before the profile are superseded by it
+	if state := m.states[config.TabOwning]; !state.loading || state.loaded {
+		t.Errorf("owning loading = %v, loaded = %v before the profile, want true, false", state.loading, state.loaded)
+	}
+
+	m = update(t, m,
+		fetchProfileMsg{profile: &github.Profile{
+			User:        &github.User{Login: "octocat"},
+			Pinned:      []github.Repository{{Owner: "octocat", Name: "pinned-repo"}},
+			Owning:      []github.Repository{{Owner: "octocat", Name: "owning-repo"}},
+			Contributed: []github.Repository{{Owner: "octocat", Name: "contributed-repo"}},
+		}},
+	)
+
+	names := map[string]string{
+		config.TabPinned:      "pinned-repo",
+		config.TabOwning:      "owning-repo",
+		config.TabContributed: "contributed-repo",
+	}
+	for tab := range m.states {
+		for other, name := range names {
+			if got, want := listed(m, tab, name), tab == other; got != want {
+				t.Errorf("%s listed %s = %v, want %v", tab, name, got, want)
+			}
+		}
+		if listed(m, tab, "early-repo") {
+			t.Errorf("%s listed early-repo, want it superseded by the profile", tab)
+		}
+	}
+}
+
+func TestModelRefetchesOnSortAndFacetsChange(t *testing.T) {
+	tests := []struct {
+		name          string
+		hasNextPage   bool
+		forksExcluded bool
+		msg           tea.Msg
+		want          bool
+	}{
+		{
+			name:        "server-side sort with more pages",
+			hasNextPage: true,
+			msg:         components.SortChangedMsg{ListType: config.TabOwning, Sort: github.SortPushed},
+			want:        true,
+		},
+		{
+			name: "server-side sort with every repository loaded",
+			msg:  components.SortChangedMsg{ListType: config.TabOwning, Sort: github.SortPushed},
+		},
+		{
+			name:        "client-side sort",
+			hasNextPage: true,
+			msg:         components.SortChangedMsg{ListType: config.TabOwning, Sort: github.SortForks},
+		},
+		{
+			name:        "forks hidden with more pages",
+			hasNextPage: true,
+			msg:         components.FacetsChangedMsg{ListType: config.TabOwning, Facets: components.Facets{HideForks: true}},
+			want:        true,
+		},
+		{
+			name: "forks hidden with every repository loaded",
+			msg:  components.FacetsChangedMsg{ListType: config.TabOwning, Facets: components.Facets{HideForks: true}},
+		},
+		{
+			name:          "forks shown after the API left them out",
+			forksExcluded: true,
+			msg:           components.FacetsChangedMsg{ListType: config.TabOwning, Facets: components.Facets{}},
+			want:          true,
+		},
+		{
+			name:        "other facets",
+			hasNextPage: true,
+			msg:         components.FacetsChangedMsg{ListType: config.TabOwning, Facets: components.Facets{HideArchived: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, config.Default())
+			msg := fetched(m, config.TabOwning, testRepositories(3))
+			msg.pageInfo.HasNextPage = tt.hasNextPage
+			msg.excludeForks = tt.forksExcluded
+			m = update(t, m, msg)
+
+			request := m.states[config.TabOwning].request
+			m = update(t, m, tt.msg)
+			if got := m.states[config.TabOwning].request != request; got != tt.want {
+				t.Errorf("refetched = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelHighlightsChangedRepositoriesAfterRefreshAll(t *testing.T) {
+	m := newTestModel(t, config.Default())
+	repos := testRepositories(2)
+	m = update(t, m, fetched(m, config.TabOwning, repos))
+
+	m.refreshAll()
+	for tab, state := range m.states {
+		if !state.loading || !state.refreshing {
+			t.Errorf("%s loading = %v, refreshing = %v after refreshAll(), want true, true", tab, state.loading, state.refreshing)
+		}
+	}
+
+	refreshed := testRepositories(3)
+	refreshed[1].StarCount = 5
+	m = update(t, m, fetched(m, config.TabOwning, refreshed))
+
+	want := []bool{false, true, true}
+	for i, repo := range refreshed {
+		if got := m.repoLists[config.TabOwning].Changed(repo); got != want[i] {
+			t.Errorf("Changed(%s) = %v, want %v", repo.Name, got, want[i])
+		}
+	}
+}
+
+func TestModelKeepsListStateOfHiddenTabOnRefresh(t *testing.T) {
+	m := newTestModel(t, config.Default())
+	owning := slices.Index(m.tabNames, config.TabOwning)
+
+	m = selectTab(t, m, owning)
+	m = update(t, m,
+		fetched(m, config.TabOwning, testRepositories(10)),
+		tea.KeyMsg{Type: tea.KeyDown},
+		tea.KeyMsg{Type: tea.KeyDown},
+		tea.KeyMsg{Type: tea.KeyDown},
+	)
+
+	m = selectTab(t, m, slices.Index(m.tabNames, config.TabPinned))
+	m.refresh(config.TabOwning)
+	m = update(t, m, fetched(m, config.TabOwning, testRepositories(12)))
+
+	m = selectTab(t, m, owning)
+	if got := m.currentList().Index(); got != 3 {
+		t.Errorf("Index() = %v, want %v", got, 3)
+	}
+	if !m.currentList().Changed(testRepositories(12)[11]) {
+		t.Errorf("Changed(repo-11) = false after the refresh, want true")
+	}
+}
@@ -0,0 +1,110 @@
+package components
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// containerMarkers are the characters of the block quote and list markers that may
+// come before a code fence on its line
+const containerMarkers = " \t>-*+.)0123456789"
+
+// codeRange is a byte range of markdown holding code
+type codeRange struct {
+	start, end int
+	block      bool // Whether the range is the whole lines of a code block, fences included
+}
+
+// findCode finds the code blocks and code spans of the markdown, in order. Code
+// spans cover their content without the backticks.
+func findCode(markdown string) []codeRange {
+	source := []byte(markdown)
+	doc := goldmark.New().Parser().Parse(text.NewReader(source))
+
+	var code []codeRange
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch n := n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			lines := n.Lines()
+			if lines.Len() == 0 {
+				return ast.WalkSkipChildren, nil
+			}
+			start := lineStart(markdown, lines.At(0).Start)
+			end := lineEnd(markdown, lines.At(lines.Len()-1).Stop)
+			if _, fenced := n.(*ast.FencedCodeBlock); fenced {
+				start, end = withFences(markdown, start, end)
+			}
+			code = append(code, codeRange{start: start, end: end, block: true})
+			return ast.WalkSkipChildren, nil
+		case *ast.CodeSpan:
+			first, firstOK := n.FirstChild().(*ast.Text)
+			last, lastOK := n.LastChild().(*ast.Text)
+			if firstOK && lastOK {
+				code = append(code, codeRange{start: first.Segment.Start, end: last.Segment.Stop})
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return code
+}
+
+// lineStart returns the offset of the start of the line holding the offset
+func lineStart(s string, offset int) int {
+	return strings.LastIndexByte(s[:offset], '\n') + 1
+}
+
+// lineEnd returns the offset after the line break ending the line that the
+// offset is on or just after, or the end of s on the last line
+func lineEnd(s string, offset int) int {
+	if offset > 0 && s[offset-1] == '\n' {
+		return offset
+	}
+	if i := strings.IndexByte(s[offset:], '\n'); i >= 0 {
+		return offset + i + 1
+	}
+	return len(s)
+}
+
+// withFences extends the lines of a fenced code block from start to end with the
+// line of its opening fence, and the line of its closing fence unless the block
+// is not closed
+func withFences(s string, start, end int) (int, int) {
+	if start == 0 {
+		return start, end
+	}
+	start = lineStart(s, start-1)
+
+	opening := strings.TrimLeft(s[start:], containerMarkers)
+	if opening == "" || end == len(s) {
+		return start, end
+	}
+	fence := strings.Repeat(opening[:1], 3)
+	next := lineEnd(s, end+1)
+	if strings.HasPrefix(strings.TrimLeft(s[end:next], containerMarkers), fence) {
+		end = next
+	}
+	return start, end
+}
+
+// maskCode returns the markdown with its code replaced by spaces, keeping line
+// breaks, so that patterns matched against it skip code while their offsets hold
+// in the markdown
+func maskCode(markdown string, code []codeRange) string {
+	masked := []byte(markdown)
+	for _, c := range code {
+		for i := c.start; i < c.end; i++ {
+			if masked[i] != '\n' {
+				masked[i] = ' '
+			}
+		}
+	}
+	return string(masked)
+}
@@ -0,0 +1,50 @@
+package components
+
+import "testing"
+
+func TestMaskCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "fenced code block",
+			markdown: "Intro\n```md\n[a](b)\n```\nOutro",
+			want:     "Intro\n     \n      \n   \nOutro",
+		},
+		{
+			name:     "other fences inside a code block",
+			markdown: "````\n~~~\n```\n````\n[a](b)\n",
+			want:     "    \n   \n   \n    \n[a](b)\n",
+		},
+		{
+			name:     "unclosed code block",
+			markdown: "~~~\n[a](b)\n",
+			want:     "   \n      \n",
+		},
+		{
+			name:     "code block in a quote",
+			markdown: "> ```\n> [a](b)\n> ```\n[a](b)",
+			want:     "     \n        \n     \n[a](b)",
+		},
+		{
+			name:     "indented code block",
+			markdown: "Intro\n\n    [a](b)\n\nOutro",
+			want:     "Intro\n\n          \n\nOutro",
+		},
+		{
+			name:     "code spans",
+			markdown: "Use `[a](b)` or ``a ` b``.",
+			want:     "Use `      ` or ``     ``.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maskCode(tt.markdown, findCode(tt.markdown)); got != tt.want {
+				t.Errorf("maskCode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
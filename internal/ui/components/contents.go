@@ -0,0 +1,76 @@
+package components
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/tnagatomi/gh-portrait/internal/ui/theme"
+)
+
+// Contents is the table of contents of the README, letting the user pick a heading
+// to jump to
+type Contents struct {
+	headings []Heading
+	cursor   int
+	styles   facetPickerStyles
+}
+
+// NewContents creates a table of contents listing the headings
+func NewContents(headings []Heading, th theme.Theme) Contents {
+	return Contents{
+		headings: headings,
+		styles:   newFacetPickerStyles(th),
+	}
+}
+
+// SetHeadings replaces the headings, such as after the README is rendered for
+// another width, keeping the cursor within them
+func (c *Contents) SetHeadings(headings []Heading) {
+	c.headings = headings
+	c.MoveCursor(0)
+}
+
+// MoveCursor moves the cursor by delta headings, staying within the headings
+func (c *Contents) MoveCursor(delta int) {
+	c.cursor = max(0, min(len(c.headings)-1, c.cursor+delta))
+}
+
+// Selected returns the heading under the cursor, if any
+func (c Contents) Selected() (Heading, bool) {
+	if c.cursor >= len(c.headings) {
+		return Heading{}, false
+	}
+	return c.headings[c.cursor], true
+}
+
+// View renders the table of contents within the given size, indenting headings by
+// their level
+func (c Contents) View(width, height int, hint string) string {
+	// The shallowest heading is not indented
+	top := 6
+	for _, heading := range c.headings {
+		top = min(top, heading.Level)
+	}
+
+	// Scroll so the cursor stays visible below the title and hint
+	visible := max(1, height-4)
+	offset := max(0, c.cursor-visible+1)
+	end := min(len(c.headings), offset+visible)
+
+	var b strings.Builder
+	b.WriteString(c.styles.title.Render("Contents") + "\n\n")
+	for i := offset; i < end; i++ {
+		heading := c.headings[i]
+		line := strings.Repeat("  ", heading.Level-top) + heading.Text
+		line = ansi.Truncate(line, max(0, width-4), "…")
+		if i == c.cursor {
+			b.WriteString(c.styles.selected.Render("> "+line) + "\n")
+		} else {
+			b.WriteString(c.styles.normal.Render("  "+line) + "\n")
+		}
+	}
+	b.WriteString("\n" + c.styles.hint.Render(hint))
+
+	return lipgloss.NewStyle().PaddingLeft(2).Render(b.String())
+}
@@ -0,0 +1,149 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tnagatomi/gh-portrait/internal/github"
+	"github.com/tnagatomi/gh-portrait/internal/ui/theme"
+)
+
+// facetToggles lists the labels of the repository kind toggles, in display order
+var facetToggles = []string{"Forks", "Archived", "Templates", "Mirrors"}
+
+// facetPickerStyles holds the styles used to render the facet picker
+type facetPickerStyles struct {
+	title    lipgloss.Style
+	selected lipgloss.Style
+	normal   lipgloss.Style
+	hint     lipgloss.Style
+}
+
+// newFacetPickerStyles creates the facet picker styles for the given theme
+func newFacetPickerStyles(th theme.Theme) facetPickerStyles {
+	return facetPickerStyles{
+		title: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(th.Accent),
+		selected: lipgloss.NewStyle().
+			Foreground(th.Accent),
+		normal: lipgloss.NewStyle(),
+		hint: lipgloss.NewStyle().
+			Foreground(th.Muted),
+	}
+}
+
+// facetPicker lets the user pick the facets of a repository list. Its options are
+// the kind toggles followed by "All languages" and each language with its count.
+type facetPicker struct {
+	facets    Facets
+	languages []LanguageCount
+	total     int
+	cursor    int
+}
+
+// newFacetPicker creates a facet picker for the repositories
+func newFacetPicker(facets Facets, repos []github.Repository) facetPicker {
+	p := facetPicker{facets: facets}
+	p.count(repos)
+	return p
+}
+
+// count recounts the languages for the current kind toggles
+func (p *facetPicker) count(repos []github.Repository) {
+	p.languages = p.facets.LanguageCounts(repos)
+	p.total = 0
+	for _, repo := range repos {
+		if p.facets.matchKind(repo) {
+			p.total++
+		}
+	}
+}
+
+// optionCount returns the number of options in the picker
+func (p facetPicker) optionCount() int {
+	return len(facetToggles) + 1 + len(p.languages)
+}
+
+// moveCursor moves the cursor by delta options, staying within the options
+func (p *facetPicker) moveCursor(delta int) {
+	p.cursor = max(0, min(p.optionCount()-1, p.cursor+delta))
+}
+
+// toggle selects the option under the cursor, recounting languages when a kind
+// toggle changes
+func (p *facetPicker) toggle(repos []github.Repository) {
+	switch p.cursor {
+	case 0:
+		p.facets.HideForks = !p.facets.HideForks
+	case 1:
+		p.facets.HideArchived = !p.facets.HideArchived
+	case 2:
+		p.facets.HideTemplates = !p.facets.HideTemplates
+	case 3:
+		p.facets.HideMirrors = !p.facets.HideMirrors
+	case len(facetToggles):
+		p.facets.Language = ""
+		return
+	default:
+		p.facets.Language = p.languages[p.cursor-len(facetToggles)-1].Language
+		return
+	}
+	p.count(repos)
+}
+
+// shown reports whether the kind toggle at index shows its repositories
+func (p facetPicker) shown(index int) bool {
+	switch index {
+	case 0:
+		return !p.facets.HideForks
+	case 1:
+		return !p.facets.HideArchived
+	case 2:
+		return !p.facets.HideTemplates
+	default:
+		return !p.facets.HideMirrors
+	}
+}
+
+// View renders the picker within the given height
+func (p facetPicker) View(height int, styles facetPickerStyles, hint string) string {
+	lines := make([]string, 0, p.optionCount())
+	for i, label := range facetToggles {
+		check := "[ ]"
+		if p.shown(i) {
+			check = "[x]"
+		}
+		lines = append(lines, fmt.Sprintf("%s %s", check, label))
+	}
+
+	radio := func(selected bool) string {
+		if selected {
+			return "(•)"
+		}
+		return "( )"
+	}
+	lines = append(lines, fmt.Sprintf("%s All languages (%d)", radio(p.facets.Language == ""), p.total))
+	for _, language := range p.languages {
+		lines = append(lines, fmt.Sprintf("%s %s (%d)", radio(p.facets.Language == language.Language), language.Language, language.Count))
+	}
+
+	// Scroll so the cursor stays visible below the title and hint
+	visible := max(1, height-4)
+	offset := max(0, p.cursor-visible+1)
+	end := min(len(lines), offset+visible)
+
+	var b strings.Builder
+	b.WriteString(styles.title.Render("Facets") + "\n\n")
+	for i := offset; i < end; i++ {
+		if i == p.cursor {
+			b.WriteString(styles.selected.Render("> "+lines[i]) + "\n")
+		} else {
+			b.WriteString(styles.normal.Render("  "+lines[i]) + "\n")
+		}
+	}
+	b.WriteString("\n" + styles.hint.Render(hint))
+
+	return lipgloss.NewStyle().PaddingLeft(2).Render(b.String())
+}
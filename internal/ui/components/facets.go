@@ -0,0 +1,104 @@
+package components
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/tnagatomi/gh-portrait/internal/github"
+)
+
+// Facets narrows down a repository list by primary language and repository kind
+type Facets struct {
+	Language      string // Primary language to show; empty shows every language
+	HideForks     bool
+	HideArchived  bool
+	HideTemplates bool
+	HideMirrors   bool
+}
+
+// LanguageCount is the number of repositories with a primary language
+type LanguageCount struct {
+	Language string
+	Count    int
+}
+
+// Match reports whether the repository is shown with the facets
+func (f Facets) Match(repo github.Repository) bool {
+	return f.matchKind(repo) && (f.Language == "" || repo.Language == f.Language)
+}
+
+// matchKind reports whether the repository passes the fork, archived, template and
+// mirror toggles
+func (f Facets) matchKind(repo github.Repository) bool {
+	switch {
+	case f.HideForks && repo.IsFork:
+		return false
+	case f.HideArchived && repo.IsArchived:
+		return false
+	case f.HideTemplates && repo.IsTemplate:
+		return false
+	case f.HideMirrors && repo.IsMirror:
+		return false
+	}
+	return true
+}
+
+// Active reports whether any facet hides repositories
+func (f Facets) Active() bool {
+	return f != Facets{}
+}
+
+// String returns a short description of the active facets
+func (f Facets) String() string {
+	var parts []string
+	if f.Language != "" {
+		parts = append(parts, f.Language)
+	}
+	if f.HideForks {
+		parts = append(parts, "no forks")
+	}
+	if f.HideArchived {
+		parts = append(parts, "no archived")
+	}
+	if f.HideTemplates {
+		parts = append(parts, "no templates")
+	}
+	if f.HideMirrors {
+		parts = append(parts, "no mirrors")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Apply returns the repositories shown with the facets
+func (f Facets) Apply(repos []github.Repository) []github.Repository {
+	matched := make([]github.Repository, 0, len(repos))
+	for _, repo := range repos {
+		if f.Match(repo) {
+			matched = append(matched, repo)
+		}
+	}
+	return matched
+}
+
+// LanguageCounts counts the primary languages of the repositories passing the kind
+// toggles, most used first. Repositories without a language are not counted.
+func (f Facets) LanguageCounts(repos []github.Repository) []LanguageCount {
+	counts := make(map[string]int)
+	for _, repo := range repos {
+		if repo.Language != "" && f.matchKind(repo) {
+			counts[repo.Language]++
+		}
+	}
+
+	languages := make([]LanguageCount, 0, len(counts))
+	for language, count := range counts {
+		languages = append(languages, LanguageCount{Language: language, Count: count})
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		if languages[i].Count != languages[j].Count {
+			return languages[i].Count > languages[j].Count
+		}
+		return languages[i].Language < languages[j].Language
+	})
+	return languages
+}
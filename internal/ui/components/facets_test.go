@@ -0,0 +1,155 @@
+package components
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tnagatomi/gh-portrait/internal/github"
+)
+
+func TestFacetsMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		facets Facets
+		repo   github.Repository
+		want   bool
+	}{
+		{
+			name: "no facets",
+			repo: github.Repository{Language: "Go", IsFork: true},
+			want: true,
+		},
+		{
+			name:   "matching language",
+			facets: Facets{Language: "Go"},
+			repo:   github.Repository{Language: "Go"},
+			want:   true,
+		},
+		{
+			name:   "other language",
+			facets: Facets{Language: "Go"},
+			repo:   github.Repository{Language: "Rust"},
+			want:   false,
+		},
+		{
+			name:   "hidden fork",
+			facets: Facets{HideForks: true},
+			repo:   github.Repository{IsFork: true},
+			want:   false,
+		},
+		{
+			name:   "hidden archived",
+			facets: Facets{HideArchived: true},
+			repo:   github.Repository{IsArchived: true},
+			want:   false,
+		},
+		{
+			name:   "hidden template",
+			facets: Facets{HideTemplates: true},
+			repo:   github.Repository{IsTemplate: true},
+			want:   false,
+		},
+		{
+			name:   "hidden mirror",
+			facets: Facets{HideMirrors: true},
+			repo:   github.Repository{IsMirror: true},
+			want:   false,
+		},
+		{
+			name:   "source repository with kinds hidden",
+			facets: Facets{HideForks: true, HideArchived: true, HideTemplates: true, HideMirrors: true},
+			repo:   github.Repository{Language: "Go"},
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.facets.Match(tt.repo); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFacetsString(t *testing.T) {
+	tests := []struct {
+		name   string
+		facets Facets
+		want   string
+	}{
+		{
+			name: "no facets",
+			want: "",
+		},
+		{
+			name:   "language",
+			facets: Facets{Language: "Go"},
+			want:   "Go",
+		},
+		{
+			name:   "language and kinds",
+			facets: Facets{Language: "Go", HideForks: true, HideMirrors: true},
+			want:   "Go, no forks, no mirrors",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.facets.String(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFacetsLanguageCounts(t *testing.T) {
+	repos := []github.Repository{
+		{Name: "a", Language: "Rust"},
+		{Name: "b", Language: "Go"},
+		{Name: "c", Language: "Go", IsFork: true},
+		{Name: "d", Language: "C"},
+		{Name: "e"},
+	}
+
+	tests := []struct {
+		name   string
+		facets Facets
+		want   []LanguageCount
+	}{
+		{
+			name: "most used first, then by name",
+			want: []LanguageCount{
+				{Language: "Go", Count: 2},
+				{Language: "C", Count: 1},
+				{Language: "Rust", Count: 1},
+			},
+		},
+		{
+			name:   "hidden kinds are not counted",
+			facets: Facets{HideForks: true},
+			want: []LanguageCount{
+				{Language: "C", Count: 1},
+				{Language: "Go", Count: 1},
+				{Language: "Rust", Count: 1},
+			},
+		},
+		{
+			name:   "selected language does not limit the counts",
+			facets: Facets{Language: "Rust"},
+			want: []LanguageCount{
+				{Language: "Go", Count: 2},
+				{Language: "C", Count: 1},
+				{Language: "Rust", Count: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.facets.LanguageCounts(repos); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LanguageCounts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
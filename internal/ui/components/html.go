@@ -0,0 +1,314 @@
+package components
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	// blankLinesPattern matches runs of blank lines
+	blankLinesPattern = regexp.MustCompile(`\n[ \t]*\n(?:[ \t]*\n)+`)
+
+	// lineBreakPattern matches a line break with the trailing spaces of its line and
+	// the indentation of the next one
+	lineBreakPattern = regexp.MustCompile(`[ \t]*\n[ \t]*`)
+
+	// markdownTextEscaper escapes the text of images and links
+	markdownTextEscaper = strings.NewReplacer("[", `\[`, "]", `\]`)
+
+	// markdownURLEscaper escapes the characters ending the destination of images
+	// and links
+	markdownURLEscaper = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29")
+)
+
+// blockTags are the HTML elements converted to paragraphs
+var blockTags = map[string]bool{
+	"p": true, "div": true, "center": true, "picture": true, "table": true, "tr": true,
+	"details": true, "blockquote": true,
+}
+
+// droppedTags are the HTML elements whose tags are dropped, keeping their content
+var droppedTags = map[string]bool{
+	"span": true, "font": true, "sup": true, "sub": true, "kbd": true, "source": true,
+	"thead": true, "tbody": true, "td": true, "th": true,
+}
+
+// htmlToMarkdown converts the HTML common in profile READMEs, such as centered
+// paragraphs of badges, images, links, headings and collapsible sections, into the
+// markdown glamour renders. Unknown tags, code blocks and code spans are left as
+// they are.
+func htmlToMarkdown(markdown string) string {
+	if !strings.Contains(markdown, "<") {
+		return markdown
+	}
+
+	var b strings.Builder
+	var spans []codeRange
+	last := 0
+	flush := func(end int) {
+		b.WriteString(convertSegment(markdown, last, end, spans))
+		spans = spans[:0]
+	}
+	for _, c := range findCode(markdown) {
+		if !c.block {
+			spans = append(spans, c)
+			continue
+		}
+		flush(c.start)
+		b.WriteString(markdown[c.start:c.end])
+		last = c.end
+	}
+	flush(len(markdown))
+	return b.String()
+}
+
+// convertSegment converts the HTML of the markdown from start to end, which holds
+// no code blocks, leaving its code spans as they are
+func convertSegment(markdown string, start, end int, spans []codeRange) string {
+	// Code spans are swapped for placeholders the converter keeps as text
+	var b strings.Builder
+	code := make([]string, 0, 2*len(spans))
+	for i, c := range spans {
+		placeholder := fmt.Sprintf("\uE000%d\uE001", i)
+		b.WriteString(markdown[start:c.start])
+		b.WriteString(placeholder)
+		code = append(code, placeholder, markdown[c.start:c.end])
+		start = c.end
+	}
+	b.WriteString(markdown[start:end])
+
+	converted := convertHTML(b.String())
+	if len(code) == 0 {
+		return converted
+	}
+	return strings.NewReplacer(code...).Replace(converted)
+}
+
+// htmlConverter converts the HTML of a README segment without code blocks
+type htmlConverter struct {
+	b        strings.Builder
+	links    []string // Destinations of the open links, empty for links without one
+	lists    []int    // Number of the next item of the open lists, 0 for bullets
+	indents  []int    // Indentation of the first line in each open converted element, -1 until known
+	headings int      // Number of open headings
+}
+
+// convertHTML converts the HTML of a README segment without code blocks
+func convertHTML(segment string) string {
+	if !strings.Contains(segment, "<") {
+		return segment
+	}
+
+	c := htmlConverter{}
+	z := html.NewTokenizer(strings.NewReader(segment))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() != io.EOF {
+				// Leave what cannot be tokenized as it is
+				c.b.Write(z.Raw())
+			}
+			break
+		}
+		c.token(tt, z.Token(), string(z.Raw()))
+	}
+
+	converted := blankLinesPattern.ReplaceAllString(c.b.String(), "\n\n")
+	// Keep the segment ending like it did, since it is followed by a code block
+	if strings.HasSuffix(segment, "\n") && !strings.HasSuffix(converted, "\n") {
+		converted += "\n"
+	}
+	return strings.TrimLeft(converted, "\n")
+}
+
+// token converts an HTML token, whose unmodified text is raw
+func (c *htmlConverter) token(tt html.TokenType, token html.Token, raw string) {
+	switch tt {
+	case html.TextToken:
+		c.text(raw)
+	case html.CommentToken:
+	case html.StartTagToken, html.SelfClosingTagToken:
+		if !c.start(token, tt == html.SelfClosingTagToken) {
+			c.b.WriteString(raw)
+		}
+	case html.EndTagToken:
+		if !c.end(token) {
+			c.b.WriteString(raw)
+		}
+	default:
+		c.b.WriteString(raw)
+	}
+}
+
+// text writes text, dropping the indentation of converted elements from its lines
+// and keeping headings on a single line
+func (c *htmlConverter) text(raw string) {
+	switch {
+	case c.headings > 0:
+		raw = lineBreakPattern.ReplaceAllString(raw, " ")
+	case len(c.indents) > 0:
+		raw = c.dedent(raw)
+	}
+	c.b.WriteString(raw)
+}
+
+// dedent drops the trailing spaces of the lines of text inside a converted element,
+// and as much indentation as the first line in the element has. Markdown indented
+// further, such as nested list items, keeps the rest of its indentation.
+func (c *htmlConverter) dedent(raw string) string {
+	indent := &c.indents[len(c.indents)-1]
+	lines := strings.Split(raw, "\n")
+	for i := 1; i < len(lines); i++ {
+		lines[i-1] = strings.TrimRight(lines[i-1], " \t")
+		line := lines[i]
+		width := len(line) - len(strings.TrimLeft(line, " \t"))
+		if width == len(line) && i < len(lines)-1 {
+			// Blank lines do not set the indentation; the last line is followed by a tag
+			lines[i] = ""
+			continue
+		}
+		if *indent < 0 {
+			*indent = width
+		}
+		lines[i] = line[min(width, *indent):]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// start converts a start tag, reporting whether it is known
+func (c *htmlConverter) start(token html.Token, selfClosing bool) bool {
+	name := token.Data
+	switch {
+	case name == "img":
+		alt := markdownTextEscaper.Replace(attr(token, "alt"))
+		c.b.WriteString("![" + alt + "](" + markdownURLEscaper.Replace(attr(token, "src")) + ")")
+		return true
+	case name == "br":
+		if c.headings > 0 {
+			c.b.WriteString(" ")
+		} else {
+			c.b.WriteString("  \n")
+		}
+		return true
+	case name == "hr":
+		c.b.WriteString("\n\n---\n\n")
+		return true
+	case selfClosing:
+		return droppedTags[name] || blockTags[name]
+	}
+
+	switch name {
+	case "a":
+		href := attr(token, "href")
+		c.links = append(c.links, href)
+		if href != "" {
+			c.b.WriteString("[")
+		}
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		c.headings++
+		c.b.WriteString("\n\n" + strings.Repeat("#", int(name[1]-'0')) + " ")
+	case "summary":
+		c.headings++
+		c.b.WriteString("\n\n**▸ ")
+	case "b", "strong":
+		// Headings and summaries are already bold
+		if c.headings == 0 {
+			c.b.WriteString("**")
+		}
+	case "i", "em":
+		c.b.WriteString("*")
+	case "code":
+		c.b.WriteString("`")
+	case "ul":
+		c.lists = append(c.lists, 0)
+		c.b.WriteString("\n\n")
+	case "ol":
+		c.lists = append(c.lists, 1)
+		c.b.WriteString("\n\n")
+	case "li":
+		c.b.WriteString("\n" + c.bullet())
+	default:
+		if blockTags[name] {
+			c.b.WriteString("\n\n")
+		} else if !droppedTags[name] {
+			return false
+		}
+	}
+	c.indents = append(c.indents, -1)
+	return true
+}
+
+// end converts an end tag, reporting whether it is known
+func (c *htmlConverter) end(token html.Token) bool {
+	name := token.Data
+	switch name {
+	case "a":
+		if len(c.links) == 0 {
+			return true
+		}
+		href := c.links[len(c.links)-1]
+		c.links = c.links[:len(c.links)-1]
+		if href != "" {
+			c.b.WriteString("](" + markdownURLEscaper.Replace(href) + ")")
+		}
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		c.headings = max(0, c.headings-1)
+		c.b.WriteString("\n\n")
+	case "summary":
+		c.headings = max(0, c.headings-1)
+		c.b.WriteString("**\n\n")
+	case "b", "strong":
+		if c.headings == 0 {
+			c.b.WriteString("**")
+		}
+	case "i", "em":
+		c.b.WriteString("*")
+	case "code":
+		c.b.WriteString("`")
+	case "ul", "ol":
+		if len(c.lists) > 0 {
+			c.lists = c.lists[:len(c.lists)-1]
+		}
+		c.b.WriteString("\n\n")
+	case "li":
+	default:
+		if blockTags[name] {
+			c.b.WriteString("\n\n")
+		} else if !droppedTags[name] {
+			return false
+		}
+	}
+	if len(c.indents) > 0 {
+		c.indents = c.indents[:len(c.indents)-1]
+	}
+	return true
+}
+
+// bullet returns the marker of the next item of the innermost list, indented by
+// its nesting
+func (c *htmlConverter) bullet() string {
+	if len(c.lists) == 0 {
+		return "- "
+	}
+	indent := strings.Repeat("  ", len(c.lists)-1)
+	n := &c.lists[len(c.lists)-1]
+	if *n == 0 {
+		return indent + "- "
+	}
+	*n++
+	return fmt.Sprintf("%s%d. ", indent, *n-1)
+}
+
+// attr returns the value of the attribute of the HTML tag, or an empty string
+func attr(token html.Token, key string) string {
+	for _, a := range token.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,75 @@
+package components
+
+import "testing"
+
+func TestHTMLToMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "markdown without HTML",
+			markdown: "# Hi\n\nSome *text*.\n",
+			want:     "# Hi\n\nSome *text*.\n",
+		},
+		{
+			name:     "centered headings",
+			markdown: "<h1 align=\"center\">Hi there\n  <img src=\"wave.gif\" width=\"28\"></h1>\n<h3 align=\"center\">A developer</h3>\n",
+			want:     "# Hi there ![](wave.gif)\n\n### A developer\n\n",
+		},
+		{
+			name:     "centered badges",
+			markdown: "<p align=\"center\">\n    <a href=\"https://example.com\"><img src=\"badge.svg\" alt=\"Build [main]\" /></a>\n    <img src=\"stats.svg\"/>\n</p>\n",
+			want:     "[![Build \\[main\\]](badge.svg)](https://example.com)\n![](stats.svg)\n\n",
+		},
+		{
+			name:     "details and summary",
+			markdown: "<details>\n  <summary>More about <b>me</b></summary>\n\n  - I like <code>Go</code>\n</details>\n",
+			want:     "**▸ More about me**\n\n- I like `Go`\n\n",
+		},
+		{
+			name:     "nested list in details",
+			markdown: "<details>\n\n- a\n  - nested\n- b\n</details>\n",
+			want:     "- a\n  - nested\n- b\n\n",
+		},
+		{
+			name:     "indented details",
+			markdown: "<details>\n  <summary>Setup</summary>\n\n  - a\n    - nested\n  - b\n</details>\n",
+			want:     "**▸ Setup**\n\n- a\n  - nested\n- b\n\n",
+		},
+		{
+			name:     "lists",
+			markdown: "<ol><li>one</li><li>two<ul><li>nested</li></ul></li></ol>\n",
+			want:     "1. one\n2. two\n\n  - nested\n\n",
+		},
+		{
+			name:     "line breaks and comments",
+			markdown: "<p>First<br>second<!-- hidden --></p>\n",
+			want:     "First  \nsecond\n\n",
+		},
+		{
+			name:     "unknown tags and autolinks are kept",
+			markdown: "Visit <https://example.org> or <my-widget>here</my-widget>.\n",
+			want:     "Visit <https://example.org> or <my-widget>here</my-widget>.\n",
+		},
+		{
+			name:     "code spans are kept",
+			markdown: "<b>Use</b> `<br>` and\n\n    <img src=\"a.png\">\n",
+			want:     "**Use** `<br>` and\n\n    <img src=\"a.png\">\n",
+		},
+		{
+			name:     "code blocks are kept",
+			markdown: "<p>Before</p>\n\n```html\n<p align=\"center\"><img src=\"a.png\"></p>\n```\n<img src=\"b.png\" alt=\"B\">\n",
+			want:     "Before\n\n```html\n<p align=\"center\"><img src=\"a.png\"></p>\n```\n![B](b.png)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := htmlToMarkdown(tt.markdown); got != tt.want {
+				t.Errorf("htmlToMarkdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,47 +1,204 @@
 package components
 
-import "github.com/charmbracelet/glamour"
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// footnoteLinkPattern matches whole inline links and images, with their text, which
+// may hold an image, destination and optional title
+var footnoteLinkPattern = regexp.MustCompile(`(!?)\[((?:[^\[\]]|\[[^\[\]]*\])*)\]\(\s*(<[^>]*>|[^\s)]+)(?:\s+(?:"[^"]*"|'[^']*'))?\s*\)`)
 
 // MarkdownRenderer defines the interface for rendering markdown content
 type MarkdownRenderer interface {
 	Render(markdown string, width int) string
 }
 
-// DefaultRenderer implements MarkdownRenderer with standard styling
+// RendererOptions configures how DefaultRenderer renders markdown
+type RendererOptions struct {
+	Style         string // Glamour style name, "auto", or path to a JSON style file
+	CodeTheme     string // Chroma theme highlighting code blocks, empty for the style's own
+	WrapWidth     int    // Widest text is wrapped at, 0 to wrap at the width rendered for
+	Emoji         bool   // Whether emoji shortcodes are rendered
+	LinkFootnotes bool   // Whether link URLs are listed as numbered footnotes
+}
+
+// DefaultRendererOptions returns the options used by NewDefaultRenderer
+func DefaultRendererOptions() RendererOptions {
+	return RendererOptions{
+		Style: "auto",
+		Emoji: true,
+	}
+}
+
+// maxRenderers is how many widths the glamour renderers are kept for
+const maxRenderers = 4
+
+// DefaultRenderer implements MarkdownRenderer with standard styling. A glamour
+// renderer is kept for each of the latest widths. It is safe to call from several
+// goroutines, but renders run one at a time as glamour renderers are not.
 type DefaultRenderer struct {
-	renderer *glamour.TermRenderer
-	width    int
+	mu        sync.Mutex
+	renderers map[int]*glamour.TermRenderer
+	widths    []int // Widths of the renderers, least recently used first
+	options   RendererOptions
 }
 
 // NewDefaultRenderer creates a new DefaultRenderer instance
 func NewDefaultRenderer() *DefaultRenderer {
-	renderer, _ := glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
-		glamour.WithEmoji(),
-		glamour.WithWordWrap(80),
-	)
+	return NewRenderer(DefaultRendererOptions())
+}
+
+// NewRenderer creates a new DefaultRenderer instance with the given options
+func NewRenderer(options RendererOptions) *DefaultRenderer {
 	return &DefaultRenderer{
-		renderer: renderer,
-		width:    80,
+		renderers: make(map[int]*glamour.TermRenderer),
+		options:   options,
+	}
+}
+
+// termRendererOptions returns the glamour options for the given width
+func (r *DefaultRenderer) termRendererOptions(width int) ([]glamour.TermRendererOption, error) {
+	style := r.options.Style
+	if style == "" {
+		style = "auto"
+	}
+
+	options := []glamour.TermRendererOption{glamour.WithWordWrap(width)}
+	if r.options.CodeTheme == "" {
+		options = append(options, glamour.WithStylePath(style))
+	} else {
+		config, err := loadStyle(style)
+		if err != nil {
+			return nil, err
+		}
+		// The theme of the style is ignored while it sets chroma colors
+		config.CodeBlock.Theme = r.options.CodeTheme
+		config.CodeBlock.Chroma = nil
+		options = append(options, glamour.WithStyles(config))
+	}
+	if r.options.Emoji {
+		options = append(options, glamour.WithEmoji())
+	}
+	return options, nil
+}
+
+// wrapWidth returns the width text is wrapped at when rendered for the given width
+func (r *DefaultRenderer) wrapWidth(width int) int {
+	if r.options.WrapWidth > 0 {
+		return min(width, r.options.WrapWidth)
+	}
+	return width
+}
+
+// ValidateStyle checks that the style is "auto", the name of a glamour style or a
+// readable JSON style file
+func ValidateStyle(style string) error {
+	if style == "" || style == styles.AutoStyle {
+		return nil
+	}
+	_, err := loadStyle(style)
+	return err
+}
+
+// loadStyle loads a glamour style by name, "auto" picking dark or light for the
+// terminal background, or from a JSON style file
+func loadStyle(style string) (ansi.StyleConfig, error) {
+	if style == styles.AutoStyle {
+		style = styles.LightStyle
+		if lipgloss.HasDarkBackground() {
+			style = styles.DarkStyle
+		}
+	}
+	if config, ok := styles.DefaultStyles[style]; ok {
+		return *config, nil
+	}
+
+	data, err := os.ReadFile(style)
+	if err != nil {
+		return ansi.StyleConfig{}, fmt.Errorf("%s: style not found", style)
+	}
+	var config ansi.StyleConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return ansi.StyleConfig{}, fmt.Errorf("%s: %w", style, err)
+	}
+	return config, nil
+}
+
+// footnoteLinks replaces the URLs of the inline links of the markdown with numbered
+// references to a list of the URLs appended to it. Images, code blocks and code
+// spans are left as they are, and links to the same URL share their number.
+func footnoteLinks(markdown string) string {
+	var urls []string
+	lines := strings.SplitAfter(markdown, "\n")
+	masked := strings.SplitAfter(maskCode(markdown, findCode(markdown)), "\n")
+	for i, line := range lines {
+		var b strings.Builder
+		last := 0
+		for _, m := range footnoteLinkPattern.FindAllStringSubmatchIndex(masked[i], -1) {
+			// Links are found with their code masked, and keep the code of their text
+			if m[3] > m[2] || line[m[6]:m[7]] != masked[i][m[6]:m[7]] {
+				continue
+			}
+			url := strings.TrimSuffix(strings.TrimPrefix(line[m[6]:m[7]], "<"), ">")
+			n := slices.Index(urls, url)
+			if n < 0 {
+				urls = append(urls, url)
+				n = len(urls) - 1
+			}
+			fmt.Fprintf(&b, "%s%s\\[%d\\]", line[last:m[0]], line[m[4]:m[5]], n+1)
+			last = m[1]
+		}
+		b.WriteString(line[last:])
+		lines[i] = b.String()
+	}
+	if len(urls) == 0 {
+		return markdown
 	}
+
+	var b strings.Builder
+	b.WriteString(strings.Join(lines, ""))
+	b.WriteString("\n\n---\n\n**Links**\n\n")
+	for i, url := range urls {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, url)
+	}
+	return b.String()
 }
 
 // Render renders markdown content with standard styling
 func (r *DefaultRenderer) Render(markdown string, width int) string {
-	if r.renderer == nil || width != r.width {
-		renderer, err := glamour.NewTermRenderer(
-			glamour.WithAutoStyle(),
-			glamour.WithEmoji(),
-			glamour.WithWordWrap(width),
-		)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Widths beyond the wrap width share its renderer
+	width = r.wrapWidth(width)
+	renderer, ok := r.renderers[width]
+	if !ok {
+		options, err := r.termRendererOptions(width)
+		if err == nil {
+			renderer, err = glamour.NewTermRenderer(options...)
+		}
 		if err != nil {
 			return "Error creating renderer: " + err.Error()
 		}
-		r.renderer = renderer
-		r.width = width
+		r.renderers[width] = renderer
 	}
+	r.useWidth(width)
 
-	rendered, err := r.renderer.Render(markdown)
+	if r.options.LinkFootnotes {
+		markdown = footnoteLinks(markdown)
+	}
+	rendered, err := renderer.Render(markdown)
 	if err != nil {
 		return "Error rendering markdown: " + err.Error()
 	}
@@ -49,6 +206,17 @@ func (r *DefaultRenderer) Render(markdown string, width int) string {
 	return rendered
 }
 
+// useWidth marks the renderer of the width as the latest used and drops the least
+// recently used ones beyond maxRenderers
+func (r *DefaultRenderer) useWidth(width int) {
+	r.widths = slices.DeleteFunc(r.widths, func(w int) bool { return w == width })
+	r.widths = append(r.widths, width)
+	for len(r.widths) > maxRenderers {
+		delete(r.renderers, r.widths[0])
+		r.widths = r.widths[1:]
+	}
+}
+
 // TestRenderer implements MarkdownRenderer with test-friendly styling
 type TestRenderer struct {
 	renderer *glamour.TermRenderer
@@ -0,0 +1,163 @@
+package components
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestFootnoteLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "links",
+			markdown: "See [my blog](https://example.com/blog \"Blog\") and [docs](<https://example.com/docs>).",
+			want:     "See my blog\\[1\\] and docs\\[2\\].\n\n---\n\n**Links**\n\n1. https://example.com/blog\n2. https://example.com/docs\n",
+		},
+		{
+			name:     "same URL shares its number",
+			markdown: "[a](https://example.com) [b](https://example.com)",
+			want:     "a\\[1\\] b\\[1\\]\n\n---\n\n**Links**\n\n1. https://example.com\n",
+		},
+		{
+			name:     "image links keep their image",
+			markdown: "[![Logo](https://example.com/logo.png)](https://example.com)",
+			want:     "![Logo](https://example.com/logo.png)\\[1\\]\n\n---\n\n**Links**\n\n1. https://example.com\n",
+		},
+		{
+			name:     "images and code blocks are kept",
+			markdown: "![Banner](https://example.com/banner.png)\n```md\n[link](https://example.com)\n```\n",
+			want:     "![Banner](https://example.com/banner.png)\n```md\n[link](https://example.com)\n```\n",
+		},
+		{
+			name:     "code spans are kept",
+			markdown: "Write `[link](https://example.com)` for [`docs`](https://example.com/docs)",
+			want:     "Write `[link](https://example.com)` for `docs`\\[1\\]\n\n---\n\n**Links**\n\n1. https://example.com/docs\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := footnoteLinks(tt.markdown); got != tt.want {
+				t.Errorf("footnoteLinks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultRendererWrapWidth(t *testing.T) {
+	markdown := strings.Repeat("word ", 40)
+
+	tests := []struct {
+		name      string
+		wrapWidth int
+		width     int
+		wantWidth int // Width rendering the same without a wrap width
+	}{
+		{
+			name:      "wraps at the width rendered for",
+			width:     60,
+			wantWidth: 60,
+		},
+		{
+			name:      "wraps at the wrap width",
+			wrapWidth: 40,
+			width:     60,
+			wantWidth: 40,
+		},
+		{
+			name:      "narrower widths than the wrap width",
+			wrapWidth: 100,
+			width:     30,
+			wantWidth: 30,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewRenderer(RendererOptions{Style: "notty", WrapWidth: tt.wrapWidth}).Render(markdown, tt.width)
+			want := NewRenderer(RendererOptions{Style: "notty"}).Render(markdown, tt.wantWidth)
+			if got != want {
+				t.Errorf("Render() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestDefaultRendererCodeTheme(t *testing.T) {
+	markdown := "```go\nfunc main() {}\n```\n"
+
+	plain := NewRenderer(RendererOptions{Style: "dark"}).Render(markdown, 80)
+	themed := NewRenderer(RendererOptions{Style: "dark", CodeTheme: "monokai"}).Render(markdown, 80)
+	if strings.HasPrefix(themed, "Error") {
+		t.Fatalf("Render() = %q", themed)
+	}
+	if ansi.Strip(themed) != ansi.Strip(plain) {
+		t.Errorf("Render() text = %q, want %q", ansi.Strip(themed), ansi.Strip(plain))
+	}
+	if themed == plain {
+		t.Error("Render() with code theme is styled like the style's own")
+	}
+}
+
+func TestDefaultRendererUnknownStyle(t *testing.T) {
+	renderer := NewRenderer(RendererOptions{Style: "nonexistent", CodeTheme: "monokai"})
+	if got := renderer.Render("# Hello", 80); !strings.HasPrefix(got, "Error creating renderer") {
+		t.Errorf("Render() = %q, want renderer error", got)
+	}
+}
+
+func TestDefaultRendererKeepsLatestWidths(t *testing.T) {
+	renderer := NewRenderer(RendererOptions{Style: "notty"})
+	for width := 40; width < 40+maxRenderers+2; width++ {
+		renderer.Render("# Hello", width)
+	}
+	renderer.Render("# Hello", 42)
+
+	want := []int{43, 44, 45, 42}
+	if !reflect.DeepEqual(renderer.widths, want) {
+		t.Errorf("widths = %v, want %v", renderer.widths, want)
+	}
+	if len(renderer.renderers) != len(want) {
+		t.Errorf("len(renderers) = %v, want %v", len(renderer.renderers), len(want))
+	}
+}
+
+func TestValidateStyle(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "style.json")
+	if err := os.WriteFile(valid, []byte(`{"document": {"margin": 1}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		style   string
+		wantErr bool
+	}{
+		{name: "auto", style: "auto"},
+		{name: "glamour style", style: "tokyo-night"},
+		{name: "style file", style: valid},
+		{name: "invalid style file", style: invalid, wantErr: true},
+		{name: "unknown style", style: "nonexistent", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateStyle(tt.style); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateStyle() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
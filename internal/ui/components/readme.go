@@ -0,0 +1,295 @@
+package components
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// Heading is a section heading of the README
+type Heading struct {
+	Level int
+	Text  string
+	Line  int // Line of the view the heading is on
+}
+
+// readmeImage is an image of the README
+type readmeImage struct {
+	alt string
+	url string
+}
+
+// readmeOutline holds the links, headings and images of a README, in order
+type readmeOutline struct {
+	links    []Link
+	headings []Heading
+	images   []readmeImage
+}
+
+// parseREADME extracts the links, headings and images of the README markdown. The
+// lines of the links and headings are located in the rendered README with locate.
+func parseREADME(markdown string) readmeOutline {
+	source := []byte(markdown)
+	doc := goldmark.New().Parser().Parse(text.NewReader(source))
+
+	var outline readmeOutline
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch n := n.(type) {
+		case *ast.Heading:
+			outline.headings = append(outline.headings, Heading{
+				Level: n.Level,
+				Text:  expandEmoji(nodeText(n, source)),
+			})
+		case *ast.Link:
+			if !IsOpenableURL(string(n.Destination)) {
+				break
+			}
+			outline.links = append(outline.links, Link{
+				Text: linkText(nodeText(n, source), string(n.Destination)),
+				URL:  string(n.Destination),
+			})
+		case *ast.Image:
+			outline.images = append(outline.images, readmeImage{
+				alt: expandEmoji(nodeText(n, source)),
+				url: string(n.Destination),
+			})
+		case *ast.AutoLink:
+			url := string(n.URL(source))
+			if n.AutoLinkType == ast.AutoLinkEmail && !strings.HasPrefix(url, "mailto:") {
+				url = "mailto:" + url
+			}
+			if !IsOpenableURL(url) {
+				break
+			}
+			outline.links = append(outline.links, Link{
+				Text: string(n.Label(source)),
+				URL:  url,
+			})
+		}
+		return ast.WalkContinue, nil
+	})
+	return outline
+}
+
+// nodeText returns the plain text of the node and its descendants, including the
+// alt text of images
+func nodeText(n ast.Node, source []byte) string {
+	var b strings.Builder
+	_ = ast.Walk(n, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Text:
+			b.Write(n.Segment.Value(source))
+			if n.SoftLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(n.Value)
+		case *ast.AutoLink:
+			b.Write(n.Label(source))
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(b.String())
+}
+
+// linkText returns the text shown for a link, its URL when it has no text such as
+// a link around an image without alt text
+func linkText(text, url string) string {
+	if text == "" {
+		return url
+	}
+	return expandEmoji(text)
+}
+
+// locate finds the lines of the links and headings in the rendered README starting
+// at line start of the view. Each is searched for after the previous one; those
+// that cannot be found, for example because they are wrapped, are placed on the
+// line of the previous one.
+func (o readmeOutline) locate(rendered string, start int) readmeOutline {
+	lines := strings.Split(ansi.Strip(rendered), "\n")
+
+	located := readmeOutline{
+		links:    make([]Link, len(o.links)),
+		headings: make([]Heading, len(o.headings)),
+		images:   o.images,
+	}
+	from := 0
+	for i, heading := range o.headings {
+		if line := findLine(lines, heading.Text, from); line >= 0 {
+			from = line
+		}
+		heading.Line = start + from
+		located.headings[i] = heading
+	}
+	from = 0
+	for i, link := range o.links {
+		if line := findLine(lines, link.Text, from); line >= 0 {
+			from = line
+		}
+		link.Line = start + from
+		located.links[i] = link
+	}
+	return located
+}
+
+// findLine returns the index of the first line from index from containing s, or -1
+func findLine(lines []string, s string, from int) int {
+	for i := from; i < len(lines); i++ {
+		if strings.Contains(lines[i], s) {
+			return i
+		}
+	}
+	return -1
+}
+
+var (
+	// inlineLinkPattern matches inline links and images with their text, which may
+	// hold an image, and destination
+	inlineLinkPattern = regexp.MustCompile(`(!?)\[((?:[^\[\]]|\[[^\[\]]*\])*)\]\(\s*(<[^>]*>|[^\s)]+)`)
+
+	// definitionPattern matches link reference definitions
+	definitionPattern = regexp.MustCompile(`^( {0,3}\[[^\]]+\]:\s*)(<[^>]*>|\S+)`)
+
+	// tagPattern matches HTML tags
+	tagPattern = regexp.MustCompile(`<[A-Za-z][^>]*>`)
+
+	// attributePattern matches the quoted src and href attributes of an HTML tag
+	attributePattern = regexp.MustCompile(`(\s(?:src|href)\s*=\s*)("[^"]*"|'[^']*')`)
+
+	// imageExtensions are the extensions of images referenced by link reference
+	// definitions, whose use is not known where they are defined
+	imageExtensions = []string{".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp"}
+)
+
+// resolveREADMEURLs rewrites the relative URLs of the README against the default
+// branch of the user's profile repository, as GitHub does: links point to the
+// files on GitHub and images to their raw content. Code blocks and code spans are
+// left as they are.
+func resolveREADMEURLs(markdown, login, branch string) string {
+	if branch == "" {
+		branch = "HEAD"
+	}
+	repo := "https://github.com/" + login + "/" + login
+
+	lines := strings.Split(markdown, "\n")
+	masked := strings.Split(maskCode(markdown, findCode(markdown)), "\n")
+	for i, line := range lines {
+		lines[i] = resolveLineURLs(line, masked[i], repo, branch)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// urlEdit replaces the URL between the byte offsets start and end of a line
+type urlEdit struct {
+	start, end int
+	url        string
+}
+
+// resolveLineURLs rewrites the relative URLs of the links, images, link reference
+// definitions and HTML tags of a line of the README. They are found in the line
+// with its code masked, so that URLs in code are left as they are.
+func resolveLineURLs(line, masked, repo, branch string) string {
+	var edits []urlEdit
+	if m := definitionPattern.FindStringSubmatchIndex(masked); m != nil {
+		dest := line[m[4]:m[5]]
+		image := slices.Contains(imageExtensions, strings.ToLower(path.Ext(strings.Trim(dest, "<>"))))
+		edits = append(edits, urlEdit{m[4], m[5], resolveDestination(dest, repo, branch, image)})
+	}
+
+	edits = append(edits, inlineLinkEdits(line, masked, 0, repo, branch)...)
+
+	for _, m := range tagPattern.FindAllStringIndex(masked, -1) {
+		tag := masked[m[0]:m[1]]
+		image := strings.HasPrefix(strings.ToLower(tag), "<img")
+		for _, a := range attributePattern.FindAllStringSubmatchIndex(tag, -1) {
+			// Only the src attribute of an image refers to an image
+			src := strings.Contains(tag[a[2]:a[3]], "src")
+			start, end := m[0]+a[4]+1, m[0]+a[5]-1
+			edits = append(edits, urlEdit{start, end, resolveURL(line[start:end], repo, branch, image && src)})
+		}
+	}
+
+	slices.SortStableFunc(edits, func(a, b urlEdit) int {
+		return a.start - b.start
+	})
+	var b strings.Builder
+	last := 0
+	for _, e := range edits {
+		// Skip URLs that overlap code or another URL
+		if e.start < last || line[e.start:e.end] != masked[e.start:e.end] {
+			continue
+		}
+		b.WriteString(line[last:e.start])
+		b.WriteString(e.url)
+		last = e.end
+	}
+	b.WriteString(line[last:])
+	return b.String()
+}
+
+// inlineLinkEdits returns the edits resolving the destinations of the inline links
+// and images in the masked text, including images inside links. The text starts
+// at the offset of the line.
+func inlineLinkEdits(line, masked string, offset int, repo, branch string) []urlEdit {
+	var edits []urlEdit
+	for _, m := range inlineLinkPattern.FindAllStringSubmatchIndex(masked, -1) {
+		edits = append(edits, inlineLinkEdits(line, masked[m[4]:m[5]], offset+m[4], repo, branch)...)
+		start, end := offset+m[6], offset+m[7]
+		image := m[3] > m[2]
+		edits = append(edits, urlEdit{start, end, resolveDestination(line[start:end], repo, branch, image)})
+	}
+	return edits
+}
+
+// resolveDestination resolves the destination of a link or image, which may be
+// enclosed in angle brackets
+func resolveDestination(dest, repo, branch string, image bool) string {
+	if inner, ok := strings.CutPrefix(dest, "<"); ok {
+		return "<" + resolveURL(strings.TrimSuffix(inner, ">"), repo, branch, image) + ">"
+	}
+	return resolveURL(dest, repo, branch, image)
+}
+
+// resolveURL resolves a relative URL of the README against the repository, to the
+// raw content of images and to the file on GitHub otherwise. Paths are relative to
+// the root of the repository, where the README is, and anchors point to the
+// README itself. Absolute URLs are returned as they are.
+func resolveURL(dest, repo, branch string, image bool) string {
+	u, err := url.Parse(dest)
+	if err != nil || dest == "" || u.Scheme != "" || u.Host != "" {
+		return dest
+	}
+
+	kind := "blob"
+	if image {
+		kind = "raw"
+	}
+	p := "/README.md"
+	if u.Path != "" {
+		// Leading ".." cannot leave the repository
+		p = path.Clean("/" + u.Path)
+	}
+
+	resolved, err := url.Parse(repo + "/" + kind + "/" + branch)
+	if err != nil {
+		return dest
+	}
+	resolved.Path += p
+	resolved.RawQuery = u.RawQuery
+	resolved.Fragment = u.Fragment
+	return resolved.String()
+}
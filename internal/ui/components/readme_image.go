@@ -0,0 +1,269 @@
+package components
+
+import (
+	"errors"
+	"image"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/tnagatomi/gh-portrait/internal/github"
+	"github.com/tnagatomi/gh-portrait/internal/termimage"
+)
+
+const (
+	// maxREADMEImages is the number of README images downloaded
+	maxREADMEImages = 16
+
+	// maxImageRows is the height of the tallest README image in terminal cells
+	maxImageRows = 20
+
+	// minImageRows is the height of the smallest README image drawn; smaller ones
+	// such as badges are left as their alt text
+	minImageRows = 2
+
+	// urlTailLength is the length of the end of image URLs searched for when they
+	// are wrapped in the rendered README
+	urlTailLength = 16
+)
+
+// READMEImageDrawnMsg is sent when a README image has been drawn in the background
+type READMEImageDrawnMsg struct {
+	url   string
+	width int
+	view  string // Drawn image, empty when it is too small to be drawn
+}
+
+// downloadedImage is a README image downloaded by the application
+type downloadedImage struct {
+	img image.Image
+	err error
+}
+
+// placedImage is a drawn README image or a placeholder, inserted below a line of
+// the rendered README
+type placedImage struct {
+	below int    // Line of the rendered README the image is inserted below
+	view  string // Drawn image or placeholder
+	link  *Link  // Link opening the image of a placeholder, nil for drawn images
+}
+
+// SetImageProtocol sets the protocol drawing README images. None shows them as
+// their alt text only.
+func (u *UserInfo) SetImageProtocol(p termimage.Protocol) {
+	if u.protocol != p {
+		u.protocol = p
+		u.releaseImageViews()
+		u.dirty = true
+	}
+}
+
+// releaseImageViews drops the drawn README images so that they are drawn again,
+// deleting them from the terminal
+func (u *UserInfo) releaseImageViews() {
+	for _, view := range u.imageViews {
+		u.release(view)
+	}
+	u.imageViews = make(map[string]string)
+}
+
+// READMEImages returns the URLs of the README images to download with SetREADMEImage,
+// leaving out those downloaded already and SVG images, which cannot be drawn
+func (u *UserInfo) READMEImages() []string {
+	if u.protocol == termimage.None {
+		return nil
+	}
+
+	var urls []string
+	for _, img := range u.outline.images {
+		if len(urls) == maxREADMEImages {
+			break
+		}
+		_, downloaded := u.images[img.url]
+		if downloaded || isSVG(img.url) || !strings.HasPrefix(img.url, "http") || slices.Contains(urls, img.url) {
+			continue
+		}
+		urls = append(urls, img.url)
+	}
+	return urls
+}
+
+// SetREADMEImage stores a README image downloaded from the URL, or shows a
+// placeholder when the error reports an image that cannot be drawn. It returns a
+// command drawing the image in the background. Images of previous READMEs are
+// ignored.
+func (u *UserInfo) SetREADMEImage(url string, img image.Image, err error) tea.Cmd {
+	if !u.showsImage(url) {
+		return nil
+	}
+	u.images[url] = downloadedImage{img: img, err: err}
+	u.release(u.imageViews[url])
+	delete(u.imageViews, url)
+	u.dirty = true
+	return u.drawREADMEImage(url)
+}
+
+// DrawREADMEImages returns a command drawing the downloaded README images for the
+// current width in the background, leaving out those drawn already
+func (u *UserInfo) DrawREADMEImages() tea.Cmd {
+	var cmds []tea.Cmd
+	for url := range u.images {
+		if _, ok := u.imageViews[url]; !ok {
+			cmds = append(cmds, u.drawREADMEImage(url))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// drawREADMEImage returns a command drawing the README image downloaded from the URL
+// for the current width, or nil when it failed to download
+func (u *UserInfo) drawREADMEImage(url string) tea.Cmd {
+	downloaded := u.images[url]
+	if downloaded.err != nil || downloaded.img == nil {
+		return nil
+	}
+
+	protocol, width := u.protocol, u.viewWidth
+	return func() tea.Msg {
+		return READMEImageDrawnMsg{url: url, width: width, view: drawImage(downloaded.img, protocol, width)}
+	}
+}
+
+// SetDrawnREADMEImage shows a README image drawn by DrawREADMEImages. Images drawn for
+// another width or of previous READMEs are ignored.
+func (u *UserInfo) SetDrawnREADMEImage(msg READMEImageDrawnMsg) {
+	if msg.width != u.viewWidth || !u.showsImage(msg.url) {
+		// The image was never shown, so the terminal has nothing to delete
+		return
+	}
+	u.release(u.imageViews[msg.url])
+	u.imageViews[msg.url] = msg.view
+	u.dirty = true
+}
+
+// showsImage reports whether the URL is an image of the README
+func (u *UserInfo) showsImage(url string) bool {
+	for _, img := range u.outline.images {
+		if img.url == url {
+			return true
+		}
+	}
+	return false
+}
+
+// isSVG reports whether the URL is an SVG image by its extension
+func isSVG(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(path.Ext(u.Path), ".svg")
+}
+
+// withImages inserts the drawn README images below the lines showing them, and
+// placeholders opening the images that cannot be drawn. It returns the links of the
+// placeholders with their line in the returned README.
+func (u *UserInfo) withImages(readme string) (string, []Link) {
+	if u.protocol == termimage.None || len(u.outline.images) == 0 {
+		return readme, nil
+	}
+
+	plain := strings.Split(ansi.Strip(readme), "\n")
+	var placed []placedImage
+	from := 0
+	for _, img := range u.outline.images {
+		line := findLine(plain, img.url, from)
+		if line < 0 && len(img.url) > urlTailLength {
+			// Long URLs are wrapped over several lines
+			line = findLine(plain, img.url[len(img.url)-urlTailLength:], from)
+		}
+		if line < 0 {
+			continue
+		}
+		from = line
+
+		if view, ok := u.imageView(img.url); ok {
+			placed = append(placed, placedImage{below: line, view: view})
+		} else if label, ok := u.placeholderLabel(img.url); ok {
+			if img.alt != "" {
+				label += ": " + img.alt
+			}
+			if !IsOpenableURL(img.url) {
+				placed = append(placed, placedImage{below: line, view: "  ▣ " + label})
+				continue
+			}
+			link := &Link{Text: "▣ " + label, URL: img.url}
+			placed = append(placed, placedImage{
+				below: line,
+				view:  "  " + u.linkView(link.Text, link.URL, false),
+				link:  link,
+			})
+		}
+	}
+	if len(placed) == 0 {
+		return readme, nil
+	}
+
+	lines := strings.Split(readme, "\n")
+	out := make([]string, 0, len(lines))
+	var links []Link
+	next := 0
+	for i, line := range lines {
+		out = append(out, line)
+		for ; next < len(placed) && placed[next].below == i; next++ {
+			if link := placed[next].link; link != nil {
+				link.Line = len(out)
+				links = append(links, *link)
+			}
+			out = append(out, strings.Split(placed[next].view, "\n")...)
+		}
+	}
+	return strings.Join(out, "\n"), links
+}
+
+// imageView returns the README image downloaded from the URL drawn for the view
+// width, if it is drawn already and was large enough to be drawn
+func (u *UserInfo) imageView(url string) (string, bool) {
+	view := u.imageViews[url]
+	return view, view != ""
+}
+
+// drawImage draws a README image indented in a view of the width, or returns an
+// empty string when it is too small to be drawn
+func drawImage(img image.Image, protocol termimage.Protocol, width int) string {
+	bounds := img.Bounds()
+	cols, rows := termimage.Cells(bounds.Dx(), bounds.Dy(), width-4, maxImageRows)
+	if rows < minImageRows {
+		return ""
+	}
+	view, err := termimage.Render(img, protocol, cols, rows)
+	if err != nil {
+		return ""
+	}
+	return "  " + strings.ReplaceAll(view, "\n", "\n  ")
+}
+
+// placeholderLabel describes the README image at the URL when it cannot be drawn,
+// reporting false for images that are not downloaded or failed to download
+func (u *UserInfo) placeholderLabel(url string) (string, bool) {
+	if isSVG(url) {
+		return "SVG image", true
+	}
+
+	err := u.images[url].err
+	switch {
+	case errors.Is(err, github.ErrSVGImage):
+		return "SVG image", true
+	case errors.Is(err, github.ErrAnimatedImage):
+		return "Animated image", true
+	case errors.Is(err, github.ErrImageTooLarge):
+		return "Large image", true
+	case errors.Is(err, github.ErrUnsupportedImage):
+		return "Image", true
+	default:
+		return "", false
+	}
+}
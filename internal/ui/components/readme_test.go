@@ -0,0 +1,368 @@
+package components
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tnagatomi/gh-portrait/internal/github"
+	"github.com/tnagatomi/gh-portrait/internal/termimage"
+	"github.com/tnagatomi/gh-portrait/internal/ui/theme"
+)
+
+func TestParseREADME(t *testing.T) {
+	tests := []struct {
+		name         string
+		markdown     string
+		wantLinks    []string // Text and URL of each link
+		wantHeadings []string // Level and text of each heading
+	}{
+		{
+			name:     "headings",
+			markdown: "# Hi there :wave:\n\nIntro\n\n## About *me*\n\nSetext\n---\n",
+			wantHeadings: []string{
+				"1 Hi there 👋",
+				"2 About me",
+				"2 Setext",
+			},
+		},
+		{
+			name:     "links",
+			markdown: "See [my blog](https://example.com/blog) and <https://example.org>.\n\nMail <octocat@example.com>",
+			wantLinks: []string{
+				"my blog", "https://example.com/blog",
+				"https://example.org", "https://example.org",
+				"octocat@example.com", "mailto:octocat@example.com",
+			},
+		},
+		{
+			name:     "image links",
+			markdown: "[![Build status](https://example.com/badge.svg)](https://example.com/ci) [![](https://example.com/logo.png)](https://example.com)",
+			wantLinks: []string{
+				"Build status", "https://example.com/ci",
+				"https://example.com", "https://example.com",
+			},
+		},
+		{
+			name:     "links in headings",
+			markdown: "## [Projects](https://example.com/projects)\n",
+			wantLinks: []string{
+				"Projects", "https://example.com/projects",
+			},
+			wantHeadings: []string{
+				"2 Projects",
+			},
+		},
+		{
+			name:     "links that cannot be opened are left out",
+			markdown: "[click](javascript:alert(1)) [home](file:///etc/passwd) <ftp://example.com> [docs](https://example.com/docs)",
+			wantLinks: []string{
+				"docs", "https://example.com/docs",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outline := parseREADME(tt.markdown)
+
+			var gotLinks []string
+			for _, link := range outline.links {
+				gotLinks = append(gotLinks, link.Text, link.URL)
+			}
+			if !slices.Equal(gotLinks, tt.wantLinks) {
+				t.Errorf("parseREADME() links = %q, want %q", gotLinks, tt.wantLinks)
+			}
+
+			var gotHeadings []string
+			for _, heading := range outline.headings {
+				gotHeadings = append(gotHeadings, fmt.Sprintf("%d %s", heading.Level, heading.Text))
+			}
+			if !slices.Equal(gotHeadings, tt.wantHeadings) {
+				t.Errorf("parseREADME() headings = %q, want %q", gotHeadings, tt.wantHeadings)
+			}
+		})
+	}
+}
+
+func TestResolveREADMEURLs(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		branch   string
+		want     string
+	}{
+		{
+			name:     "relative link",
+			markdown: "See [the docs](./docs/guide.md).",
+			branch:   "main",
+			want:     "See [the docs](https://github.com/octocat/octocat/blob/main/docs/guide.md).",
+		},
+		{
+			name:     "relative image",
+			markdown: "![Banner](assets/banner.png)",
+			branch:   "main",
+			want:     "![Banner](https://github.com/octocat/octocat/raw/main/assets/banner.png)",
+		},
+		{
+			name:     "nested paths",
+			markdown: "[Post](posts/2024/../2025/hello.md) [Root](/LICENSE) [Up](../../x.md)",
+			branch:   "main",
+			want:     "[Post](https://github.com/octocat/octocat/blob/main/posts/2025/hello.md) [Root](https://github.com/octocat/octocat/blob/main/LICENSE) [Up](https://github.com/octocat/octocat/blob/main/x.md)",
+		},
+		{
+			name:     "anchors",
+			markdown: "[Top](#about-me) [Section](docs/guide.md#install)",
+			branch:   "main",
+			want:     "[Top](https://github.com/octocat/octocat/blob/main/README.md#about-me) [Section](https://github.com/octocat/octocat/blob/main/docs/guide.md#install)",
+		},
+		{
+			name:     "image inside a link",
+			markdown: "[![Logo](img/logo.svg)](projects/)",
+			branch:   "trunk",
+			want:     "[![Logo](https://github.com/octocat/octocat/raw/trunk/img/logo.svg)](https://github.com/octocat/octocat/blob/trunk/projects)",
+		},
+		{
+			name:     "absolute URLs are kept",
+			markdown: "[Blog](https://example.com/a.md) ![](//example.com/b.png) [Mail](mailto:octocat@example.com)",
+			branch:   "main",
+			want:     "[Blog](https://example.com/a.md) ![](//example.com/b.png) [Mail](mailto:octocat@example.com)",
+		},
+		{
+			name:     "reference definitions",
+			markdown: "[docs]: docs/index.md\n[logo]: <assets/my logo.PNG>",
+			branch:   "main",
+			want:     "[docs]: https://github.com/octocat/octocat/blob/main/docs/index.md\n[logo]: <https://github.com/octocat/octocat/raw/main/assets/my%20logo.PNG>",
+		},
+		{
+			name:     "HTML tags",
+			markdown: `<a href="stats.md"><img src='stats.svg' width="400"></a>`,
+			branch:   "main",
+			want:     `<a href="https://github.com/octocat/octocat/blob/main/stats.md"><img src='https://github.com/octocat/octocat/raw/main/stats.svg' width="400"></a>`,
+		},
+		{
+			name:     "code blocks are kept",
+			markdown: "```md\n[link](docs.md)\n```\n[link](docs.md)",
+			branch:   "main",
+			want:     "```md\n[link](docs.md)\n```\n[link](https://github.com/octocat/octocat/blob/main/docs.md)",
+		},
+		{
+			name:     "code spans and indented code blocks are kept",
+			markdown: "Write `[link](docs.md)` or `<img src=\"a.png\">`\n\n    [link](docs.md)\n\n[`docs`](docs.md)",
+			branch:   "main",
+			want:     "Write `[link](docs.md)` or `<img src=\"a.png\">`\n\n    [link](docs.md)\n\n[`docs`](https://github.com/octocat/octocat/blob/main/docs.md)",
+		},
+		{
+			name:     "other fences inside a code block",
+			markdown: "```\n~~~\n[link](docs.md)\n```",
+			branch:   "main",
+			want:     "```\n~~~\n[link](docs.md)\n```",
+		},
+		{
+			name:     "unknown branch",
+			markdown: "[link](docs.md)",
+			want:     "[link](https://github.com/octocat/octocat/blob/HEAD/docs.md)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveREADMEURLs(tt.markdown, "octocat", tt.branch); got != tt.want {
+				t.Errorf("resolveREADMEURLs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserInfoREADMEOutline(t *testing.T) {
+	readme := "# Hello\n\nRead [the docs](https://example.com/docs).\n\n## Projects\n\nSee [gh-portrait](https://github.com/tnagatomi/gh-portrait).\n"
+	user := &github.User{
+		Login:  "octocat",
+		Name:   "The Octocat",
+		README: &readme,
+	}
+	ui := NewUserInfo(user, NewTestRenderer(), theme.DarkTheme())
+
+	// Nothing is located before the README is rendered
+	ui.View()
+	if len(ui.Headings()) != 0 || len(ui.links) != 0 {
+		t.Fatalf("Headings() = %v and links = %v before rendering, want none", ui.Headings(), ui.links)
+	}
+
+	renderREADME(&ui)
+	lines := strings.Split(ui.View(), "\n")
+	lineOf := func(s string) int {
+		for i, line := range lines {
+			if strings.Contains(line, s) {
+				return i
+			}
+		}
+		t.Fatalf("View() does not contain %q", s)
+		return -1
+	}
+
+	wantHeadings := []Heading{
+		{Level: 1, Text: "Hello", Line: lineOf("# Hello")},
+		{Level: 2, Text: "Projects", Line: lineOf("## Projects")},
+	}
+	if got := ui.Headings(); !slices.Equal(got, wantHeadings) {
+		t.Errorf("Headings() = %v, want %v", got, wantHeadings)
+	}
+
+	wantLinks := []Link{
+		{Text: "the docs", URL: "https://example.com/docs", Line: lineOf("the docs")},
+		{Text: "gh-portrait", URL: "https://github.com/tnagatomi/gh-portrait", Line: lineOf("See gh-portrait")},
+	}
+	if !slices.Equal(ui.links, wantLinks) {
+		t.Fatalf("links = %v, want %v", ui.links, wantLinks)
+	}
+
+	// The line of the selected README link is marked
+	ui.NextLink()
+	ui.NextLink()
+	if link, _ := ui.SelectedLink(); link.URL != wantLinks[1].URL {
+		t.Fatalf("SelectedLink() = %v, want %v", link, wantLinks[1])
+	}
+	lines = strings.Split(ui.View(), "\n")
+	if got := lines[wantLinks[1].Line]; !strings.Contains(got, "▶") {
+		t.Errorf("View() selected link line = %q, want marker", got)
+	}
+}
+
+func TestUserInfoREADMEImages(t *testing.T) {
+	const (
+		banner   = "https://example.com/banner.png"
+		badge    = "https://example.com/badge.png"
+		stats    = "https://example.com/stats.svg"
+		animated = "https://example.com/typing.gif"
+	)
+	readme := "# Hello\n\n![Banner](" + banner + ")\n\n![Badge](" + badge + ") ![Stats](" + stats + ")\n\n![Typing](" + animated + ")\n"
+	user := &github.User{Login: "octocat", Name: "The Octocat", README: &readme}
+	ui := NewUserInfo(user, NewTestRenderer(), theme.DarkTheme())
+
+	// Images are not downloaded until a protocol draws them
+	if got := ui.READMEImages(); len(got) != 0 {
+		t.Errorf("READMEImages() without protocol = %v, want none", got)
+	}
+	ui.SetImageProtocol(termimage.Halfblocks)
+	wantURLs := []string{banner, badge, animated}
+	if got := ui.READMEImages(); !slices.Equal(got, wantURLs) {
+		t.Errorf("READMEImages() = %v, want %v", got, wantURLs)
+	}
+
+	solid := func(width, height int) image.Image {
+		img := image.NewRGBA(image.Rect(0, 0, width, height))
+		for y := range height {
+			for x := range width {
+				img.SetRGBA(x, y, color.RGBA{R: 255, A: 255})
+			}
+		}
+		return img
+	}
+	drawREADMEImage(&ui, ui.SetREADMEImage(banner, solid(400, 80), nil))
+	drawREADMEImage(&ui, ui.SetREADMEImage(badge, solid(90, 20), nil))
+	drawREADMEImage(&ui, ui.SetREADMEImage(animated, nil, github.ErrAnimatedImage))
+	drawREADMEImage(&ui, ui.SetREADMEImage("https://example.com/other.png", solid(400, 80), nil))
+	renderREADME(&ui)
+	view := ui.View()
+
+	// The banner is drawn below its line and the badge is too small to be drawn
+	if got := strings.Count(view, "▀"); got != 40*4 {
+		t.Errorf("View() has %d half blocks, want %d", got, 40*4)
+	}
+	if got := ui.READMEImages(); len(got) != 0 {
+		t.Errorf("READMEImages() after downloading = %v, want none", got)
+	}
+
+	// Images are drawn again for a new width, and not shown until they are
+	ui.SetWidth(34)
+	renderREADME(&ui)
+	if got := strings.Count(ui.View(), "▀"); got != 0 {
+		t.Errorf("View() has %d half blocks before drawing for the new width, want none", got)
+	}
+	drawREADMEImage(&ui, ui.DrawREADMEImages())
+	if got := strings.Count(ui.View(), "▀"); got != 30*3 {
+		t.Errorf("View() has %d half blocks for the new width, want %d", got, 30*3)
+	}
+	ui.SetWidth(80)
+	renderREADME(&ui)
+	drawREADMEImage(&ui, ui.DrawREADMEImages())
+	view = ui.View()
+
+	// Images that cannot be drawn are placeholders that can be opened
+	var placeholders []Link
+	for _, link := range ui.links {
+		if strings.HasPrefix(link.Text, "▣") {
+			placeholders = append(placeholders, link)
+		}
+	}
+	lines := strings.Split(view, "\n")
+	wantTexts := []string{"▣ SVG image: Stats", "▣ Animated image: Typing"}
+	if len(placeholders) != len(wantTexts) {
+		t.Fatalf("placeholders = %v, want %v", placeholders, wantTexts)
+	}
+	for i, link := range placeholders {
+		if link.Text != wantTexts[i] {
+			t.Errorf("placeholder %d = %q, want %q", i, link.Text, wantTexts[i])
+		}
+		if !strings.Contains(lines[link.Line], wantTexts[i]) {
+			t.Errorf("placeholder %d line = %q, want %q", i, lines[link.Line], wantTexts[i])
+		}
+	}
+}
+
+func TestUserInfoReleasesREADMEImages(t *testing.T) {
+	banner := "https://example.com/banner.png"
+	readme := "![Banner](" + banner + ")\n"
+	user := &github.User{Login: "octocat", README: &readme}
+	ui := NewUserInfo(user, NewTestRenderer(), theme.DarkTheme())
+	ui.SetImageProtocol(termimage.Kitty)
+	drawREADMEImage(&ui, ui.SetREADMEImage(banner, image.NewRGBA(image.Rect(0, 0, 400, 80)), nil))
+	view := ui.imageViews[banner]
+
+	if got := ui.TakeReleasedImages(); got != "" {
+		t.Errorf("TakeReleasedImages() before replacing = %q, want empty", got)
+	}
+
+	// Drawing the image again for a new width deletes the image shown before
+	ui.SetWidth(60)
+	want := termimage.Release(view)
+	if got := ui.TakeReleasedImages(); want == "" || got != want {
+		t.Errorf("TakeReleasedImages() = %q, want %q", got, want)
+	}
+	if got := ui.TakeReleasedImages(); got != "" {
+		t.Errorf("TakeReleasedImages() twice = %q, want empty", got)
+	}
+}
+
+func TestContents(t *testing.T) {
+	headings := []Heading{
+		{Level: 2, Text: "About", Line: 10},
+		{Level: 3, Text: "Skills", Line: 14},
+		{Level: 2, Text: "Projects", Line: 20},
+	}
+	contents := NewContents(headings, theme.DarkTheme())
+
+	view := contents.View(40, 20, "")
+	if !strings.Contains(view, "> About") || !strings.Contains(view, "    Skills") {
+		t.Errorf("View() = %q, want cursor on About and Skills indented", view)
+	}
+
+	contents.MoveCursor(5)
+	if got, _ := contents.Selected(); got != headings[2] {
+		t.Errorf("Selected() = %v, want %v", got, headings[2])
+	}
+
+	contents.SetHeadings(headings[:1])
+	if got, _ := contents.Selected(); got != headings[0] {
+		t.Errorf("Selected() after SetHeadings() = %v, want %v", got, headings[0])
+	}
+
+	contents.SetHeadings(nil)
+	if _, ok := contents.Selected(); ok {
+		t.Error("Selected() ok = true without headings, want false")
+	}
+}
@@ -0,0 +1,142 @@
+package components
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ellipsis is appended to text truncated to the list width
+const ellipsis = "…"
+
+// repositoryDelegate renders repository items like list.DefaultDelegate, but
+// highlights filter matches in the description as well as in the title, URLs,
+// @mentions and issue references in the description, and repositories that
+// changed in the latest refresh
+type repositoryDelegate struct {
+	list.DefaultDelegate
+	changed   lipgloss.Style
+	reference lipgloss.Style
+}
+
+// newRepositoryDelegate creates a repositoryDelegate with the given base delegate
+// and styles for changed repositories and references
+func newRepositoryDelegate(base list.DefaultDelegate, changed, reference lipgloss.Style) repositoryDelegate {
+	return repositoryDelegate{DefaultDelegate: base, changed: changed, reference: reference}
+}
+
+// Render renders a repository item
+func (d repositoryDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	i, ok := item.(RepositoryItem)
+	if !ok || m.Width() <= 0 {
+		return
+	}
+
+	var (
+		s     = &d.Styles
+		title = i.Title()
+		desc  = i.Description()
+	)
+
+	// Prevent text from exceeding list width
+	textwidth := m.Width() - s.NormalTitle.GetPaddingLeft() - s.NormalTitle.GetPaddingRight()
+	title = ansi.Truncate(title, textwidth, ellipsis)
+	desc = ansi.Truncate(strings.SplitN(desc, "\n", 2)[0], textwidth, ellipsis)
+
+	// Conditions
+	var (
+		isSelected  = index == m.Index()
+		emptyFilter = m.FilterState() == list.Filtering && m.FilterValue() == ""
+		isFiltered  = m.FilterState() == list.Filtering || m.FilterState() == list.FilterApplied
+	)
+
+	var titleMatches, descMatches []int
+	if isFiltered && index < len(m.VisibleItems()) {
+		titleMatches, descMatches = i.splitMatches(m.MatchesForItem(index))
+	}
+
+	titleStyle, descStyle := s.NormalTitle, s.NormalDesc
+	if isSelected && m.FilterState() != list.Filtering {
+		titleStyle, descStyle = s.SelectedTitle, s.SelectedDesc
+	}
+	if i.changed {
+		titleStyle = titleStyle.Foreground(d.changed.GetForeground()).Bold(d.changed.GetBold())
+		descStyle = descStyle.Foreground(d.changed.GetForeground())
+	}
+
+	if emptyFilter {
+		title = s.DimmedTitle.Render(title)
+		desc = s.DimmedDesc.Render(desc)
+	} else {
+		if isFiltered {
+			title = highlightRunes(title, titleMatches, titleStyle, s.FilterMatch)
+		}
+		desc = highlightReferences(desc, descMatches, descStyle, s.FilterMatch, d.reference)
+		title = titleStyle.Render(title)
+		desc = descStyle.Render(desc)
+	}
+
+	fmt.Fprintf(w, "%s\n%s", title, desc) //nolint: errcheck
+}
+
+// highlightRunes styles the runes at the given indexes with the match style
+func highlightRunes(s string, indexes []int, base, match lipgloss.Style) string {
+	if len(indexes) == 0 {
+		return s
+	}
+	unmatched := base.Inline(true)
+	matched := unmatched.Inherit(match)
+	return lipgloss.StyleRunes(s, indexes, matched, unmatched)
+}
+
+// highlightReferences styles the URLs, @mentions and issue references in the text
+// with the reference style, and the runes at the given indexes with the match style
+func highlightReferences(s string, indexes []int, base, match, reference lipgloss.Style) string {
+	refs := findReferences(s)
+	if len(refs) == 0 {
+		return highlightRunes(s, indexes, base, match)
+	}
+
+	unmatched := base.Inline(true)
+	referenced := reference.Inherit(unmatched)
+	styles := [2][2]lipgloss.Style{
+		{unmatched, unmatched.Inherit(match)},
+		{referenced, referenced.Inherit(match)},
+	}
+
+	var b, run strings.Builder
+	var current [2]int
+	flush := func() {
+		if run.Len() > 0 {
+			b.WriteString(styles[current[0]][current[1]].Render(run.String()))
+			run.Reset()
+		}
+	}
+
+	index := 0
+	for offset, r := range s {
+		for len(refs) > 0 && offset >= refs[0].end {
+			refs = refs[1:]
+		}
+		var state [2]int
+		if len(refs) > 0 && offset >= refs[0].start {
+			state[0] = 1
+		}
+		if slices.Contains(indexes, index) {
+			state[1] = 1
+		}
+		if state != current {
+			flush()
+			current = state
+		}
+		run.WriteRune(r)
+		index++
+	}
+	flush()
+	return b.String()
+}
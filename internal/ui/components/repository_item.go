@@ -2,6 +2,8 @@ package components
 
 import (
 	"fmt"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/tnagatomi/gh-portrait/internal/github"
 )
@@ -10,6 +12,7 @@ import (
 type RepositoryItem struct {
 	repository github.Repository
 	listType   string
+	changed    bool // Whether the repository changed in the latest refresh
 }
 
 // Title returns the repository name and language
@@ -27,16 +30,50 @@ func (r RepositoryItem) Title() string {
 	return name
 }
 
+// description returns the repository description with emoji shortcodes expanded
+func (r RepositoryItem) description() string {
+	return expandEmoji(r.repository.Description)
+}
+
 // Description returns the repository description and star count
 func (r RepositoryItem) Description() string {
-	desc := r.repository.Description
+	desc := r.description()
 	if desc == "" {
 		desc = "No description"
 	}
 	return fmt.Sprintf("%s (%d stars)", desc, r.repository.StarCount)
 }
 
-// FilterValue returns the value to use for filtering
+// FilterValue returns the value to use for filtering. It starts with the title
+// (name and language) followed by the description, owner and topics, so matches
+// in the title and description can be highlighted.
 func (r RepositoryItem) FilterValue() string {
-	return r.repository.Name
+	parts := []string{r.Title()}
+	if desc := r.description(); desc != "" {
+		parts = append(parts, desc)
+	}
+	if r.listType != "contributed" && r.repository.Owner != "" {
+		parts = append(parts, r.repository.Owner)
+	}
+	parts = append(parts, r.repository.Topics...)
+	return strings.Join(parts, " ")
+}
+
+// splitMatches splits rune indexes matched in FilterValue into indexes within
+// Title and Description. Matches in the owner and topics are not shown.
+func (r RepositoryItem) splitMatches(matches []int) (title, desc []int) {
+	titleLen := utf8.RuneCountInString(r.Title())
+	descStart := titleLen + 1
+	descEnd := descStart
+	descEnd += utf8.RuneCountInString(r.description())
+
+	for _, m := range matches {
+		switch {
+		case m < titleLen:
+			title = append(title, m)
+		case m >= descStart && m < descEnd:
+			desc = append(desc, m-descStart)
+		}
+	}
+	return title, desc
 }
\ No newline at end of file
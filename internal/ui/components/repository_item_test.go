@@ -1,8 +1,11 @@
 package components
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/tnagatomi/gh-portrait/internal/github"
 )
 
@@ -93,6 +96,16 @@ func TestRepositoryItemDescription(t *testing.T) {
 			},
 			expected: "No description (10 stars)",
 		},
+		{
+			name: "repository with emoji shortcodes in description",
+			item: RepositoryItem{
+				repository: github.Repository{
+					Description: ":sparkles: Dotfiles",
+					StarCount:   3,
+				},
+			},
+			expected: "✨ Dotfiles (3 stars)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -105,16 +118,116 @@ func TestRepositoryItemDescription(t *testing.T) {
 	}
 }
 
+func TestHighlightReferences(t *testing.T) {
+	// Transforms show through without a color profile, unlike colors
+	base := lipgloss.NewStyle()
+	match := lipgloss.NewStyle().Transform(strings.ToLower)
+	reference := lipgloss.NewStyle().Transform(strings.ToUpper)
+
+	tests := []struct {
+		name     string
+		text     string
+		matches  []int
+		expected string
+	}{
+		{
+			name:     "no references",
+			text:     "✨ Dotfiles (3 stars)",
+			expected: "✨ Dotfiles (3 stars)",
+		},
+		{
+			name:     "mention and issue reference",
+			text:     "Fork of @octocat/hello, see cli/cli#1 (3 stars)",
+			expected: "Fork of @OCTOCAT/HELLO, see CLI/CLI#1 (3 stars)",
+		},
+		{
+			name:     "matches outside references",
+			text:     "Tools by @bob (3 stars)",
+			matches:  []int{0, 1},
+			expected: "tools by @BOB (3 stars)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := highlightReferences(tt.text, tt.matches, base, match, reference)
+			if got != tt.expected {
+				t.Errorf("highlightReferences() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestRepositoryItemFilterValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     RepositoryItem
+		expected string
+	}{
+		{
+			name: "repository with name only",
+			item: RepositoryItem{
+				repository: github.Repository{
+					Name: "gh-portrait",
+				},
+			},
+			expected: "gh-portrait",
+		},
+		{
+			name: "repository with all fields (owning)",
+			item: RepositoryItem{
+				repository: github.Repository{
+					Owner:       "tnagatomi",
+					Name:        "gh-portrait",
+					Language:    "Go",
+					Description: "GitHub profile viewer",
+					Topics:      []string{"cli", "tui"},
+				},
+				listType: "owning",
+			},
+			expected: "gh-portrait (Go) GitHub profile viewer tnagatomi cli tui",
+		},
+		{
+			name: "owner is part of the title (contributed)",
+			item: RepositoryItem{
+				repository: github.Repository{
+					Owner: "cli",
+					Name:  "cli",
+				},
+				listType: "contributed",
+			},
+			expected: "cli/cli",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.item.FilterValue()
+			if got != tt.expected {
+				t.Errorf("FilterValue() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRepositoryItemSplitMatches(t *testing.T) {
 	item := RepositoryItem{
 		repository: github.Repository{
-			Name: "gh-portrait",
+			Owner:       "tnagatomi",
+			Name:        "gh",
+			Description: "viewer",
+			Topics:      []string{"tui"},
 		},
+		listType: "owning",
 	}
 
-	got := item.FilterValue()
-	expected := "gh-portrait"
-	if got != expected {
-		t.Errorf("FilterValue() = %v, want %v", got, expected)
+	// FilterValue is "gh viewer tnagatomi tui"
+	gotTitle, gotDesc := item.splitMatches([]int{0, 1, 3, 8, 10, 20})
+
+	if want := []int{0, 1}; !reflect.DeepEqual(gotTitle, want) {
+		t.Errorf("splitMatches() title = %v, want %v", gotTitle, want)
+	}
+	if want := []int{0, 5}; !reflect.DeepEqual(gotDesc, want) {
+		t.Errorf("splitMatches() desc = %v, want %v", gotDesc, want)
 	}
 }
\ No newline at end of file
@@ -1,64 +1,372 @@
 package components
 
 import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/tnagatomi/gh-portrait/internal/github"
+	"github.com/tnagatomi/gh-portrait/internal/ui/theme"
 )
 
-var (
-	titleStyle = lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("86"))
-)
+// highlightDuration is how long repositories that changed in a refresh stay highlighted
+const highlightDuration = 3 * time.Second
+
+// sortPinnedOrder keeps pinned repositories in the order chosen by the user
+const sortPinnedOrder github.SortField = ""
 
 // RepositorySelectedMsg is sent when a repository is selected
 type RepositorySelectedMsg struct {
 	Repository *github.Repository
 }
 
+// SortChangedMsg is sent when the sort order of a repository list is changed
+type SortChangedMsg struct {
+	ListType string
+	Sort     github.SortField
+}
+
+// FacetsChangedMsg is sent when the facets of a repository list are changed
+type FacetsChangedMsg struct {
+	ListType string
+	Facets   Facets
+}
+
+// HighlightExpiredMsg is sent when the highlight of changed repositories ends
+type HighlightExpiredMsg struct {
+	ListType string
+	id       int
+}
+
+// RepositoryListKeyMap defines the key bindings handled by RepositoryList
+type RepositoryListKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Open   key.Binding
+	Filter key.Binding
+	Sort   key.Binding
+	Facets key.Binding
+
+	// Reserved are the keys of the application, which the list must not handle
+	Reserved []key.Binding
+}
+
+// DefaultRepositoryListKeyMap returns the default RepositoryList key bindings
+func DefaultRepositoryListKeyMap() RepositoryListKeyMap {
+	return RepositoryListKeyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "down"),
+		),
+		Open: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "open in browser"),
+		),
+		Filter: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "filter"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "sort"),
+		),
+		Facets: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "facets"),
+		),
+	}
+}
+
 // RepositoryList represents a list of repositories
 type RepositoryList struct {
-	list     list.Model
-	selected *github.Repository
-	listType string
+	list         list.Model
+	selected     *github.Repository
+	listType     string
+	keys         RepositoryListKeyMap
+	repositories []github.Repository
+	sort         github.SortField
+	facets       Facets
+	picker       facetPicker
+	pickerOpen   bool
+	pickerStyles facetPickerStyles
+	loading      string
+	skeleton     lipgloss.Style
+	changed      map[string]bool // Repositories changed in the latest refresh, by full name
+	highlight    int             // Number of the latest highlight, to ignore expired ones
 }
 
 // NewRepositoryList creates a new RepositoryList
-func NewRepositoryList(repositories []github.Repository, listType string) RepositoryList {
-	items := make([]list.Item, len(repositories))
-	for i, repo := range repositories {
-		items[i] = RepositoryItem{repository: repo, listType: listType}
-	}
-
+func NewRepositoryList(repositories []github.Repository, listType string, th theme.Theme) RepositoryList {
 	delegate := list.NewDefaultDelegate()
-	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.Foreground(lipgloss.Color("86"))
-	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.Foreground(lipgloss.Color("243"))
+	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
+		Foreground(th.Accent).
+		BorderForeground(th.Accent)
+	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
+		Foreground(th.Subtle).
+		BorderForeground(th.Accent)
+	delegate.Styles.FilterMatch = delegate.Styles.FilterMatch.
+		Foreground(th.Accent)
 
-	l := list.New(items, delegate, 0, 0)
+	changed := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(th.Accent)
+	reference := lipgloss.NewStyle().
+		Foreground(th.Accent).
+		Underline(true)
+	l := list.New(nil, newRepositoryDelegate(delegate, changed, reference), 0, 0)
 	l.SetShowHelp(false)
-	l.SetShowStatusBar(false)
-	l.SetFilteringEnabled(false)
-	l.Styles.Title = titleStyle
+	l.SetStatusBarItemName("repository", "repositories")
+	l.Styles.Title = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(th.Accent)
+	l.Styles.StatusBar = l.Styles.StatusBar.Foreground(th.Muted)
+	l.Styles.FilterPrompt = lipgloss.NewStyle()
+	l.Styles.FilterCursor = lipgloss.NewStyle()
+
+	// Quitting and help are handled by the application
+	l.KeyMap.Quit.SetEnabled(false)
+	l.KeyMap.ForceQuit.SetEnabled(false)
+	l.KeyMap.ShowFullHelp.SetEnabled(false)
+	l.KeyMap.CloseFullHelp.SetEnabled(false)
+
+	r := RepositoryList{
+		list:         l,
+		selected:     nil,
+		listType:     listType,
+		sort:         sortCycle(listType)[0],
+		pickerStyles: newFacetPickerStyles(th),
+		skeleton:     lipgloss.NewStyle().Foreground(th.Muted),
+	}
+	r.SetKeyMap(DefaultRepositoryListKeyMap())
+	r.SetRepositories(repositories)
+
+	return r
+}
 
+// sortCycle returns the sort orders available for the list type, starting with
+// the default order
+func sortCycle(listType string) []github.SortField {
+	if listType == "pinned" {
+		return append([]github.SortField{sortPinnedOrder}, github.SortFields...)
+	}
+	return github.SortFields
+}
+
+// listTitle returns the list title, including the active sort order and facets
+func listTitle(listType string, sort github.SortField, facets Facets) string {
+	var title string
 	switch listType {
 	case "pinned":
-		l.Title = "Pinned repositories"
+		title = "Pinned repositories"
 	case "owning":
-		l.Title = "Most starred repositories"
+		title = "Owned repositories"
 	case "contributed":
-		l.Title = "Most starred contributed repositories (in the past year)"
+		title = "Contributed repositories (in the past year)"
 	}
 
-	l.Styles.FilterPrompt = lipgloss.NewStyle()
-	l.Styles.FilterCursor = lipgloss.NewStyle()
+	if sort != sortPinnedOrder || listType != "pinned" {
+		title += " · sorted by " + sort.Label()
+	}
+	if facets.Active() {
+		title += " · " + facets.String()
+	}
+	return title
+}
+
+// updateTitle sets the list title from the sort order, facets and loading status
+func (r *RepositoryList) updateTitle() {
+	r.list.Title = listTitle(r.listType, r.sort, r.facets)
+	if r.loading != "" {
+		r.list.Title += "  " + r.loading
+	}
+}
+
+// SetLoading shows a loading status, such as a spinner with the elapsed time, while
+// repositories are fetched. Skeleton rows are shown until the first repositories
+// arrive. An empty status ends loading.
+func (r *RepositoryList) SetLoading(status string) {
+	r.loading = status
+	r.updateTitle()
+}
 
-	return RepositoryList{
-		list:     l,
-		selected: nil,
-		listType: listType,
+// skeletonView renders placeholder rows shaped like the delegate's items
+func (r RepositoryList) skeletonView() string {
+	widths := []int{18, 12, 24, 15, 21, 10}
+
+	var b strings.Builder
+	b.WriteString(r.list.Styles.TitleBar.Render(r.list.Styles.Title.Render(r.list.Title)) + "\n")
+	b.WriteString(r.list.Styles.StatusBar.Render("") + "\n")
+	for i := range max(0, (r.list.Height()-4)/3) {
+		width := widths[i%len(widths)]
+		b.WriteString(r.skeleton.Render("  "+strings.Repeat("░", width)) + "\n")
+		b.WriteString(r.skeleton.Render("  "+strings.Repeat("░", width*2)) + "\n\n")
 	}
+	return lipgloss.NewStyle().
+		Width(r.list.Width()).
+		MaxHeight(r.list.Height()).
+		Render(strings.TrimSuffix(b.String(), "\n"))
+}
+
+// SetKeyMap sets the key bindings used to navigate the list and open repositories
+func (r *RepositoryList) SetKeyMap(keys RepositoryListKeyMap) {
+	r.keys = keys
+	r.list.KeyMap.CursorUp = keys.Up
+	r.list.KeyMap.CursorDown = keys.Down
+	r.list.KeyMap.Filter = keys.Filter
+
+	// Keys bound to other actions must not turn the page as well, as "f" does by
+	// default
+	bound := append([]key.Binding{keys.Up, keys.Down, keys.Open, keys.Filter, keys.Sort, keys.Facets}, keys.Reserved...)
+	defaults := list.DefaultKeyMap()
+	r.list.KeyMap.PrevPage = withoutKeys(defaults.PrevPage, bound)
+	r.list.KeyMap.NextPage = withoutKeys(defaults.NextPage, bound)
+	r.list.KeyMap.GoToStart = withoutKeys(defaults.GoToStart, bound)
+	r.list.KeyMap.GoToEnd = withoutKeys(defaults.GoToEnd, bound)
+}
+
+// withoutKeys returns the binding without the keys of the bound bindings
+func withoutKeys(b key.Binding, bound []key.Binding) key.Binding {
+	var keys []string
+	for _, k := range b.Keys() {
+		taken := false
+		for _, other := range bound {
+			if slices.Contains(other.Keys(), k) {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			keys = append(keys, k)
+		}
+	}
+	b.SetKeys(keys...)
+	b.SetEnabled(len(keys) > 0)
+	return b
+}
+
+// SetRepositories replaces the repositories shown in the list, keeping the active
+// sort order, facets and filter
+func (r *RepositoryList) SetRepositories(repositories []github.Repository) tea.Cmd {
+	r.repositories = repositories
+	return r.SetSort(r.sort)
+}
+
+// RefreshRepositories replaces the repositories like SetRepositories, highlighting
+// the ones that are new or changed for a moment
+func (r *RepositoryList) RefreshRepositories(repositories []github.Repository) tea.Cmd {
+	var expire tea.Cmd
+	if r.repositories != nil {
+		r.changed = changedRepositories(r.repositories, repositories)
+		if len(r.changed) > 0 {
+			r.highlight++
+			msg := HighlightExpiredMsg{ListType: r.listType, id: r.highlight}
+			expire = tea.Tick(highlightDuration, func(time.Time) tea.Msg { return msg })
+		}
+	}
+
+	return tea.Batch(r.SetRepositories(repositories), expire)
+}
+
+// fullName returns the owner and name of the repository
+func fullName(repo github.Repository) string {
+	return repo.Owner + "/" + repo.Name
+}
+
+// changedRepositories returns the full names of the repositories that are new or
+// whose shown details changed
+func changedRepositories(old, repositories []github.Repository) map[string]bool {
+	previous := make(map[string]github.Repository, len(old))
+	for _, repo := range old {
+		previous[fullName(repo)] = repo
+	}
+
+	changed := make(map[string]bool)
+	for _, repo := range repositories {
+		prev, ok := previous[fullName(repo)]
+		if !ok ||
+			prev.StarCount != repo.StarCount ||
+			prev.ForkCount != repo.ForkCount ||
+			prev.Description != repo.Description ||
+			prev.Language != repo.Language ||
+			prev.IsArchived != repo.IsArchived ||
+			!prev.PushedAt.Equal(repo.PushedAt) {
+			changed[fullName(repo)] = true
+		}
+	}
+	return changed
+}
+
+// Facets returns the active facets
+func (r RepositoryList) Facets() Facets {
+	return r.facets
+}
+
+// SetFacets narrows the list down to the repositories matching the facets
+func (r *RepositoryList) SetFacets(facets Facets) tea.Cmd {
+	r.facets = facets
+	return r.SetSort(r.sort)
+}
+
+// Sort returns the active sort order
+func (r RepositoryList) Sort() github.SortField {
+	return r.sort
+}
+
+// SetSort sorts the list by the given field
+func (r *RepositoryList) SetSort(sort github.SortField) tea.Cmd {
+	r.sort = sort
+	r.updateTitle()
+
+	sorted := r.facets.Apply(r.repositories)
+	if sort != sortPinnedOrder {
+		github.SortRepositories(sorted, sort)
+	}
+
+	items := make([]list.Item, len(sorted))
+	for i, repo := range sorted {
+		items[i] = RepositoryItem{
+			repository: repo,
+			listType:   r.listType,
+			changed:    r.changed[fullName(repo)],
+		}
+	}
+	return r.list.SetItems(items)
+}
+
+// nextSort cycles to the next sort order and reports the change
+func (r *RepositoryList) nextSort() tea.Cmd {
+	cycle := sortCycle(r.listType)
+	next := cycle[0]
+	for i, sort := range cycle {
+		if sort == r.sort {
+			next = cycle[(i+1)%len(cycle)]
+			break
+		}
+	}
+
+	cmd := r.SetSort(next)
+	r.list.ResetSelected()
+
+	msg := SortChangedMsg{ListType: r.listType, Sort: next}
+	return tea.Batch(cmd, func() tea.Msg { return msg })
+}
+
+// CapturesKey reports whether the list handles the key itself instead of the
+// application, such as while a filter is typed, when clearing an applied filter or
+// while the facet picker is open
+func (r RepositoryList) CapturesKey(msg tea.KeyMsg) bool {
+	if r.pickerOpen || r.list.SettingFilter() {
+		return true
+	}
+	return r.list.IsFiltered() && key.Matches(msg, r.list.KeyMap.ClearFilter)
 }
 
 // SetSize sets the size of the list
@@ -66,28 +374,83 @@ func (r *RepositoryList) SetSize(width, height int) {
 	r.list.SetSize(width, height)
 }
 
+// updatePicker handles keys while the facet picker is open
+func (r *RepositoryList) updatePicker(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, r.keys.Facets), msg.Type == tea.KeyEsc:
+		r.pickerOpen = false
+	case key.Matches(msg, r.keys.Up):
+		r.picker.moveCursor(-1)
+	case key.Matches(msg, r.keys.Down):
+		r.picker.moveCursor(1)
+	case key.Matches(msg, r.keys.Open), msg.Type == tea.KeySpace:
+		r.picker.toggle(r.repositories)
+		if r.picker.facets == r.facets {
+			return nil
+		}
+		cmd := r.SetFacets(r.picker.facets)
+		r.list.ResetSelected()
+
+		msg := FacetsChangedMsg{ListType: r.listType, Facets: r.facets}
+		return tea.Batch(cmd, func() tea.Msg { return msg })
+	}
+	return nil
+}
+
 // Update handles list updates
 func (r *RepositoryList) Update(msg tea.Msg) (*RepositoryList, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && r.pickerOpen {
+		return r, r.updatePicker(msg)
+	}
+
+	// Keys typed into the filter must not open repositories
+	settingFilter := r.list.SettingFilter()
+
 	var cmd tea.Cmd
 	r.list, cmd = r.list.Update(msg)
 
 	switch msg := msg.(type) {
+	case HighlightExpiredMsg:
+		if msg.ListType == r.listType && msg.id == r.highlight {
+			r.changed = nil
+			return r, tea.Batch(cmd, r.SetSort(r.sort))
+		}
 	case tea.KeyMsg:
-		if msg.String() == "enter" {
+		if settingFilter {
+			break
+		}
+		switch {
+		case key.Matches(msg, r.keys.Open):
 			if i, ok := r.list.SelectedItem().(RepositoryItem); ok {
 				r.selected = &i.repository
 				return r, func() tea.Msg {
 					return RepositorySelectedMsg{Repository: r.selected}
 				}
 			}
+		case key.Matches(msg, r.keys.Sort):
+			return r, tea.Batch(cmd, r.nextSort())
+		case key.Matches(msg, r.keys.Facets):
+			r.picker = newFacetPicker(r.facets, r.repositories)
+			r.pickerOpen = true
 		}
 	}
 
 	return r, cmd
 }
 
-// View renders the list
+// View renders the list, the facet picker while it is open, or skeleton rows while
+// the first repositories are fetched
 func (r RepositoryList) View() string {
+	if r.loading != "" && len(r.repositories) == 0 {
+		return r.skeletonView()
+	}
+	if r.pickerOpen {
+		hint := strings.Join([]string{
+			r.keys.Open.Help().Key + "/space toggle",
+			r.keys.Facets.Help().Key + "/esc close",
+		}, " • ")
+		return r.picker.View(r.list.Height(), r.pickerStyles, hint)
+	}
 	return r.list.View()
 }
 
@@ -95,3 +458,19 @@ func (r RepositoryList) View() string {
 func (r RepositoryList) Selected() *github.Repository {
 	return r.selected
 }
+
+// Changed reports whether the repository is highlighted as new or changed by the
+// latest refresh
+func (r RepositoryList) Changed(repo github.Repository) bool {
+	return r.changed[fullName(repo)]
+}
+
+// FilterValue returns the text the list is filtered by
+func (r RepositoryList) FilterValue() string {
+	return r.list.FilterValue()
+}
+
+// Index returns the index of the selected item among the shown items
+func (r RepositoryList) Index() int {
+	return r.list.Index()
+}
@@ -1,9 +1,16 @@
 package components
 
 import (
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/tnagatomi/gh-portrait/internal/github"
+	"github.com/tnagatomi/gh-portrait/internal/ui/theme"
 )
 
 func TestNewRepositoryList(t *testing.T) {
@@ -29,7 +36,7 @@ func TestNewRepositoryList(t *testing.T) {
 				{Name: "gh-portrait"},
 				{Name: "cli"},
 			},
-			wantTitle: "Most starred repositories",
+			wantTitle: "Owned repositories · sorted by stars",
 		},
 		{
 			name:     "contributed repositories",
@@ -38,13 +45,13 @@ func TestNewRepositoryList(t *testing.T) {
 				{Name: "gh-portrait"},
 				{Name: "cli"},
 			},
-			wantTitle: "Most starred contributed repositories (in the past year)",
+			wantTitle: "Contributed repositories (in the past year) · sorted by stars",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			list := NewRepositoryList(tt.repos, tt.listType)
+			list := NewRepositoryList(tt.repos, tt.listType, theme.DarkTheme())
 
 			// Check title
 			if list.list.Title != tt.wantTitle {
@@ -71,7 +78,7 @@ func TestNewRepositoryList(t *testing.T) {
 
 func TestRepositoryListSetSize(t *testing.T) {
 	repos := []github.Repository{{Name: "gh-portrait"}}
-	list := NewRepositoryList(repos, "owning")
+	list := NewRepositoryList(repos, "owning", theme.DarkTheme())
 
 	width, height := 100, 50
 	list.SetSize(width, height)
@@ -83,10 +90,397 @@ func TestRepositoryListSetSize(t *testing.T) {
 
 func TestRepositoryListSelected(t *testing.T) {
 	repos := []github.Repository{{Name: "gh-portrait"}}
-	list := NewRepositoryList(repos, "owning")
+	list := NewRepositoryList(repos, "owning", theme.DarkTheme())
 
 	// Initially, no repository should be selected
 	if got := list.Selected(); got != nil {
 		t.Errorf("Selected() = %v, want nil", got)
 	}
 }
+
+func TestRepositoryListOpen(t *testing.T) {
+	repos := []github.Repository{{Name: "gh-portrait"}, {Name: "cli"}}
+
+	tests := []struct {
+		name     string
+		keys     RepositoryListKeyMap
+		key      tea.KeyMsg
+		wantOpen bool
+	}{
+		{
+			name:     "default open key",
+			keys:     DefaultRepositoryListKeyMap(),
+			key:      tea.KeyMsg{Type: tea.KeyEnter},
+			wantOpen: true,
+		},
+		{
+			name: "custom open key",
+			keys: func() RepositoryListKeyMap {
+				keys := DefaultRepositoryListKeyMap()
+				keys.Open = key.NewBinding(key.WithKeys("o"))
+				return keys
+			}(),
+			key:      tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")},
+			wantOpen: true,
+		},
+		{
+			name: "default key after remapping",
+			keys: func() RepositoryListKeyMap {
+				keys := DefaultRepositoryListKeyMap()
+				keys.Open = key.NewBinding(key.WithKeys("o"))
+				return keys
+			}(),
+			key:      tea.KeyMsg{Type: tea.KeyEnter},
+			wantOpen: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := NewRepositoryList(repos, "owning", theme.DarkTheme())
+			list.SetKeyMap(tt.keys)
+
+			list.Update(tt.key)
+
+			got := list.Selected()
+			if (got != nil) != tt.wantOpen {
+				t.Fatalf("Selected() = %v, want open %v", got, tt.wantOpen)
+			}
+			if got != nil && got.Name != "gh-portrait" {
+				t.Errorf("Selected() name = %v, want gh-portrait", got.Name)
+			}
+		})
+	}
+}
+
+func TestRepositoryListFilter(t *testing.T) {
+	repos := []github.Repository{{Name: "gh-portrait"}, {Name: "cli"}}
+	list := NewRepositoryList(repos, "owning", theme.DarkTheme())
+	list.SetSize(80, 20)
+
+	if list.CapturesKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}) {
+		t.Error("CapturesKey() = true before filtering, want false")
+	}
+
+	// Start filtering
+	list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	if !list.CapturesKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}) {
+		t.Error("CapturesKey() = false while filtering, want true")
+	}
+
+	// Accepting the filter must not open a repository
+	list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	list.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := list.Selected(); got != nil {
+		t.Errorf("Selected() = %v after accepting filter, want nil", got)
+	}
+
+	// Escape clears the applied filter instead of quitting
+	if !list.CapturesKey(tea.KeyMsg{Type: tea.KeyEsc}) {
+		t.Error("CapturesKey(esc) = false with applied filter, want true")
+	}
+}
+
+func TestRepositoryListSort(t *testing.T) {
+	repos := []github.Repository{
+		{Name: "b", StarCount: 1, ForkCount: 5},
+		{Name: "a", StarCount: 3, ForkCount: 0},
+		{Name: "c", StarCount: 2, ForkCount: 9},
+	}
+
+	tests := []struct {
+		name      string
+		listType  string
+		presses   int
+		wantSort  github.SortField
+		wantNames []string
+		wantTitle string
+	}{
+		{
+			name:      "pinned keeps user order by default",
+			listType:  "pinned",
+			wantSort:  "",
+			wantNames: []string{"b", "a", "c"},
+			wantTitle: "Pinned repositories",
+		},
+		{
+			name:      "pinned sorted by stars",
+			listType:  "pinned",
+			presses:   1,
+			wantSort:  github.SortStars,
+			wantNames: []string{"a", "c", "b"},
+			wantTitle: "Pinned repositories · sorted by stars",
+		},
+		{
+			name:      "owning sorted by stars by default",
+			listType:  "owning",
+			wantSort:  github.SortStars,
+			wantNames: []string{"a", "c", "b"},
+			wantTitle: "Owned repositories · sorted by stars",
+		},
+		{
+			name:      "owning sorted by forks",
+			listType:  "owning",
+			presses:   1,
+			wantSort:  github.SortForks,
+			wantNames: []string{"c", "b", "a"},
+			wantTitle: "Owned repositories · sorted by forks",
+		},
+		{
+			name:      "sort cycles back to the default",
+			listType:  "owning",
+			presses:   len(github.SortFields),
+			wantSort:  github.SortStars,
+			wantNames: []string{"a", "c", "b"},
+			wantTitle: "Owned repositories · sorted by stars",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := NewRepositoryList(repos, tt.listType, theme.DarkTheme())
+
+			for range tt.presses {
+				list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+			}
+
+			if got := list.Sort(); got != tt.wantSort {
+				t.Errorf("Sort() = %v, want %v", got, tt.wantSort)
+			}
+			if list.list.Title != tt.wantTitle {
+				t.Errorf("title = %v, want %v", list.list.Title, tt.wantTitle)
+			}
+
+			items := list.list.Items()
+			got := make([]string, len(items))
+			for i, item := range items {
+				got[i] = item.(RepositoryItem).repository.Name
+			}
+			if !reflect.DeepEqual(got, tt.wantNames) {
+				t.Errorf("items = %v, want %v", got, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestRepositoryListFacets(t *testing.T) {
+	repos := []github.Repository{
+		{Name: "a", StarCount: 4, Language: "Go"},
+		{Name: "b", StarCount: 3, Language: "Go", IsFork: true},
+		{Name: "c", StarCount: 2, Language: "Rust"},
+		{Name: "d", StarCount: 1, Language: "Go", IsArchived: true},
+	}
+
+	tests := []struct {
+		name       string
+		keys       []tea.KeyMsg
+		wantFacets Facets
+		wantNames  []string
+		wantTitle  string
+	}{
+		{
+			name:      "no facets",
+			wantNames: []string{"a", "b", "c", "d"},
+			wantTitle: "Owned repositories · sorted by stars",
+		},
+		{
+			name: "hide forks",
+			keys: []tea.KeyMsg{
+				{Type: tea.KeyRunes, Runes: []rune("f")},
+				{Type: tea.KeySpace},
+				{Type: tea.KeyEsc},
+			},
+			wantFacets: Facets{HideForks: true},
+			wantNames:  []string{"a", "c", "d"},
+			wantTitle:  "Owned repositories · sorted by stars · no forks",
+		},
+		{
+			name: "hide archived and pick the most used language",
+			keys: []tea.KeyMsg{
+				{Type: tea.KeyRunes, Runes: []rune("f")},
+				{Type: tea.KeyDown},
+				{Type: tea.KeySpace},
+				{Type: tea.KeyDown},
+				{Type: tea.KeyDown},
+				{Type: tea.KeyDown},
+				{Type: tea.KeyDown},
+				{Type: tea.KeyEnter},
+				{Type: tea.KeyRunes, Runes: []rune("f")},
+			},
+			wantFacets: Facets{Language: "Go", HideArchived: true},
+			wantNames:  []string{"a", "b"},
+			wantTitle:  "Owned repositories · sorted by stars · Go, no archived",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := NewRepositoryList(repos, "owning", theme.DarkTheme())
+
+			for _, msg := range tt.keys {
+				list.Update(msg)
+			}
+
+			if list.pickerOpen {
+				t.Errorf("pickerOpen = true, want false")
+			}
+			if got := list.Facets(); got != tt.wantFacets {
+				t.Errorf("Facets() = %+v, want %+v", got, tt.wantFacets)
+			}
+			if list.list.Title != tt.wantTitle {
+				t.Errorf("title = %v, want %v", list.list.Title, tt.wantTitle)
+			}
+
+			items := list.list.Items()
+			got := make([]string, len(items))
+			for i, item := range items {
+				got[i] = item.(RepositoryItem).repository.Name
+			}
+			if !reflect.DeepEqual(got, tt.wantNames) {
+				t.Errorf("items = %v, want %v", got, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestRepositoryListFacetsKeyKeepsPage(t *testing.T) {
+	repos := make([]github.Repository, 40)
+	for i := range repos {
+		repos[i] = github.Repository{Name: fmt.Sprintf("repo-%d", i)}
+	}
+	list := NewRepositoryList(repos, "owning", theme.DarkTheme())
+	list.SetSize(80, 20)
+
+	list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	list.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	if got := list.list.Paginator.Page; got != 0 {
+		t.Errorf("Paginator.Page = %v, want 0", got)
+	}
+}
+
+func TestRepositoryListLoading(t *testing.T) {
+	tests := []struct {
+		name         string
+		repos        []github.Repository
+		status       string
+		wantSkeleton bool
+		wantTitle    string
+	}{
+		{
+			name:         "skeleton rows while the first repositories are fetched",
+			status:       "⠋ 2s",
+			wantSkeleton: true,
+			wantTitle:    "Owned repositories · sorted by stars  ⠋ 2s",
+		},
+		{
+			name:      "repositories shown while reloading",
+			repos:     []github.Repository{{Name: "a"}},
+			status:    "⠋ 2s",
+			wantTitle: "Owned repositories · sorted by stars  ⠋ 2s",
+		},
+		{
+			name:      "loaded",
+			repos:     []github.Repository{{Name: "a"}},
+			wantTitle: "Owned repositories · sorted by stars",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := NewRepositoryList(tt.repos, "owning", theme.DarkTheme())
+			list.SetSize(80, 20)
+			list.SetLoading(tt.status)
+
+			if list.list.Title != tt.wantTitle {
+				t.Errorf("title = %v, want %v", list.list.Title, tt.wantTitle)
+			}
+			if got := strings.Contains(list.View(), "░"); got != tt.wantSkeleton {
+				t.Errorf("View() shows skeleton = %v, want %v", got, tt.wantSkeleton)
+			}
+		})
+	}
+}
+
+func TestChangedRepositories(t *testing.T) {
+	pushed := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	old := []github.Repository{
+		{Owner: "o", Name: "a", StarCount: 1, PushedAt: pushed},
+		{Owner: "o", Name: "b", StarCount: 2, PushedAt: pushed},
+	}
+
+	tests := []struct {
+		name  string
+		repos []github.Repository
+		want  map[string]bool
+	}{
+		{
+			name:  "unchanged",
+			repos: old,
+			want:  map[string]bool{},
+		},
+		{
+			name: "star count changed",
+			repos: []github.Repository{
+				{Owner: "o", Name: "a", StarCount: 5, PushedAt: pushed},
+				{Owner: "o", Name: "b", StarCount: 2, PushedAt: pushed},
+			},
+			want: map[string]bool{"o/a": true},
+		},
+		{
+			name: "pushed again",
+			repos: []github.Repository{
+				{Owner: "o", Name: "b", StarCount: 2, PushedAt: pushed.Add(time.Hour)},
+			},
+			want: map[string]bool{"o/b": true},
+		},
+		{
+			name: "new repository",
+			repos: []github.Repository{
+				{Owner: "o", Name: "a", StarCount: 1, PushedAt: pushed},
+				{Owner: "o", Name: "c"},
+			},
+			want: map[string]bool{"o/c": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := changedRepositories(old, tt.repos); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changedRepositories() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepositoryListRefreshRepositories(t *testing.T) {
+	list := NewRepositoryList([]github.Repository{{Owner: "o", Name: "a", StarCount: 1}}, "owning", theme.DarkTheme())
+	list.RefreshRepositories([]github.Repository{
+		{Owner: "o", Name: "a", StarCount: 2},
+		{Owner: "o", Name: "b"},
+	})
+
+	changed := func() []bool {
+		items := list.list.Items()
+		got := make([]bool, len(items))
+		for i, item := range items {
+			got[i] = item.(RepositoryItem).changed
+		}
+		return got
+	}
+
+	if got, want := changed(), []bool{true, true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("changed after refresh = %v, want %v", got, want)
+	}
+
+	// An expired highlight of an earlier refresh is ignored
+	list.Update(HighlightExpiredMsg{ListType: "owning", id: list.highlight - 1})
+	if got, want := changed(), []bool{true, true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("changed after earlier highlight expired = %v, want %v", got, want)
+	}
+
+	list.Update(HighlightExpiredMsg{ListType: "owning", id: list.highlight})
+	if got, want := changed(), []bool{false, false}; !reflect.DeepEqual(got, want) {
+		t.Errorf("changed after highlight expired = %v, want %v", got, want)
+	}
+}
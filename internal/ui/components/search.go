@@ -0,0 +1,136 @@
+package components
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Match is a search match in the view, spanning the cells from Start to End of its
+// line
+type Match struct {
+	Line  int
+	Start int
+	End   int
+}
+
+// findMatches finds the case-insensitive matches of the query in the text shown by
+// the view, ignoring its escape sequences
+func findMatches(view, query string) []Match {
+	if query == "" {
+		return nil
+	}
+
+	var matches []Match
+	for i, line := range strings.Split(ansi.Strip(view), "\n") {
+		for start := 0; start < len(line); {
+			end, ok := matchAt(line, start, query)
+			if !ok {
+				_, size := utf8.DecodeRuneInString(line[start:])
+				start += size
+				continue
+			}
+			matches = append(matches, Match{
+				Line:  i,
+				Start: ansi.StringWidth(line[:start]),
+				End:   ansi.StringWidth(line[:end]),
+			})
+			start = end
+		}
+	}
+	return matches
+}
+
+// matchAt reports whether the query matches s at byte offset i, ignoring case, and
+// returns the offset the match ends at
+func matchAt(s string, i int, query string) (int, bool) {
+	for _, q := range query {
+		if i >= len(s) {
+			return 0, false
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if unicode.ToLower(r) != unicode.ToLower(q) {
+			return 0, false
+		}
+		i += size
+	}
+	return i, true
+}
+
+// highlightMatches highlights the matches in the view, the current one with its own
+// style, keeping the styles of the text around them
+func highlightMatches(view string, matches []Match, current int, style, currentStyle lipgloss.Style) string {
+	if len(matches) == 0 {
+		return view
+	}
+
+	// Matches are highlighted from the end so that those left to highlight keep
+	// their cells
+	lines := strings.Split(view, "\n")
+	for i := len(matches) - 1; i >= 0; i-- {
+		match := matches[i]
+		if match.Line >= len(lines) {
+			continue
+		}
+		line := lines[match.Line]
+		text := ansi.Strip(ansi.Cut(line, match.Start, match.End))
+
+		s := style
+		if i == current {
+			s = currentStyle
+		}
+		lines[match.Line] = ansi.Truncate(line, match.Start, "") + s.Render(text) + ansi.TruncateLeft(line, match.End, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetSearch highlights the matches of the query in the view, selecting the first
+// one. An empty query clears the search.
+func (u *UserInfo) SetSearch(query string) {
+	if u.query == query {
+		return
+	}
+	u.query = query
+	u.match = 0
+	u.dirty = true
+}
+
+// SearchQuery returns the query searched for, empty when there is no search
+func (u *UserInfo) SearchQuery() string {
+	return u.query
+}
+
+// NextMatch selects the next search match, wrapping around
+func (u *UserInfo) NextMatch() {
+	if len(u.matches) == 0 {
+		return
+	}
+	u.match = (u.match + 1) % len(u.matches)
+	u.dirty = true
+}
+
+// PrevMatch selects the previous search match, wrapping around
+func (u *UserInfo) PrevMatch() {
+	if len(u.matches) == 0 {
+		return
+	}
+	u.match = (u.match - 1 + len(u.matches)) % len(u.matches)
+	u.dirty = true
+}
+
+// CurrentMatch returns the selected search match and its index among the matches,
+// as of the last View
+func (u *UserInfo) CurrentMatch() (Match, int, bool) {
+	if u.match >= len(u.matches) {
+		return Match{}, 0, false
+	}
+	return u.matches[u.match], u.match, true
+}
+
+// MatchCount returns the number of search matches as of the last View
+func (u *UserInfo) MatchCount() int {
+	return len(u.matches)
+}
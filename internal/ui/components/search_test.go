@@ -0,0 +1,116 @@
+package components
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/tnagatomi/gh-portrait/internal/github"
+	"github.com/tnagatomi/gh-portrait/internal/ui/theme"
+)
+
+func TestFindMatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		view  string
+		query string
+		want  []Match
+	}{
+		{
+			name:  "case-insensitive",
+			view:  "Go gopher\nGOLANG",
+			query: "go",
+			want: []Match{
+				{Line: 0, Start: 0, End: 2},
+				{Line: 0, Start: 3, End: 5},
+				{Line: 1, Start: 0, End: 2},
+			},
+		},
+		{
+			name:  "escape sequences are ignored",
+			view:  "\x1b[1m  Name:\x1b[0m \x1b]8;;https://example.com\x1b\\The Octocat\x1b]8;;\x1b\\",
+			query: "octocat",
+			want:  []Match{{Line: 0, Start: 12, End: 19}},
+		},
+		{
+			name:  "wide characters",
+			view:  "日本 go",
+			query: "go",
+			want:  []Match{{Line: 0, Start: 5, End: 7}},
+		},
+		{
+			name:  "overlapping matches",
+			view:  "aaaa",
+			query: "aa",
+			want:  []Match{{Line: 0, Start: 0, End: 2}, {Line: 0, Start: 2, End: 4}},
+		},
+		{
+			name:  "empty query",
+			view:  "Go",
+			query: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findMatches(tt.view, tt.query); !slices.Equal(got, tt.want) {
+				t.Errorf("findMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	style := lipgloss.NewStyle().Transform(func(s string) string { return "<" + s + ">" })
+	current := lipgloss.NewStyle().Transform(func(s string) string { return "[" + s + "]" })
+
+	view := "\x1b[31mHello world, world\x1b[0m\nworld"
+	matches := findMatches(view, "WORLD")
+	got := highlightMatches(view, matches, 1, style, current)
+
+	if want := "Hello <world>, [world]\n<world>"; ansi.Strip(got) != want {
+		t.Errorf("highlightMatches() = %q, want %q", ansi.Strip(got), want)
+	}
+	// The text after a match keeps its style
+	if want := "\x1b[31m, "; !strings.Contains(got, want) {
+		t.Errorf("highlightMatches() = %q, want it to contain %q", got, want)
+	}
+}
+
+func TestUserInfoSearch(t *testing.T) {
+	user := &github.User{
+		Name: "The Octocat",
+		Bio:  "Octocat loves Go",
+	}
+	ui := NewUserInfo(user, NewTestRenderer(), theme.DarkTheme())
+
+	ui.SetSearch("octocat")
+	ui.View()
+	if got := ui.MatchCount(); got != 2 {
+		t.Fatalf("MatchCount() = %v, want %v", got, 2)
+	}
+	if _, index, _ := ui.CurrentMatch(); index != 0 {
+		t.Errorf("CurrentMatch() index = %v, want %v", index, 0)
+	}
+
+	ui.NextMatch()
+	ui.View()
+	if match, index, _ := ui.CurrentMatch(); index != 1 || match.Line != 2 {
+		t.Errorf("CurrentMatch() = %v, %v after NextMatch(), want line 2, index 1", match, index)
+	}
+	ui.NextMatch()
+	ui.PrevMatch()
+	ui.PrevMatch()
+	ui.View()
+	if _, index, _ := ui.CurrentMatch(); index != 0 {
+		t.Errorf("CurrentMatch() index = %v after wrapping around, want %v", index, 0)
+	}
+
+	ui.SetSearch("")
+	ui.View()
+	if _, _, ok := ui.CurrentMatch(); ok || ui.MatchCount() != 0 {
+		t.Errorf("CurrentMatch() ok = %v and MatchCount() = %v after clearing, want none", ok, ui.MatchCount())
+	}
+}
@@ -2,33 +2,46 @@ package components
 
 import (
 	"github.com/charmbracelet/lipgloss"
+	"github.com/tnagatomi/gh-portrait/internal/ui/theme"
 )
 
-var (
-	activeTabStyle = lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("86"))
+// tabStyles holds the styles used to render tabs
+type tabStyles struct {
+	active   lipgloss.Style
+	inactive lipgloss.Style
+	gap      string
+}
 
-	inactiveTabStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("241"))
+// newTabStyles creates the tab styles for the given theme
+func newTabStyles(th theme.Theme) tabStyles {
+	inactive := lipgloss.NewStyle().
+		Foreground(th.Muted)
 
-	tabGap = inactiveTabStyle.Render("  ")
-)
+	return tabStyles{
+		active: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(th.Accent),
+		inactive: inactive,
+		gap:      inactive.Render("  "),
+	}
+}
 
 // Tab represents a single tab
 type Tab struct {
 	Title    string
 	Selected bool
+	Hidden   bool // Whether the tab is left out of the tab bar and skipped when cycling
 }
 
 // Tabs represents a collection of tabs
 type Tabs struct {
 	Tabs    []Tab
 	Current int
+	styles  tabStyles
 }
 
 // NewTabs creates a new Tabs instance
-func NewTabs(titles []string) Tabs {
+func NewTabs(titles []string, th theme.Theme) Tabs {
 	tabs := make([]Tab, len(titles))
 	for i, title := range titles {
 		tabs[i] = Tab{
@@ -39,20 +52,51 @@ func NewTabs(titles []string) Tabs {
 	return Tabs{
 		Tabs:    tabs,
 		Current: 0,
+		styles:  newTabStyles(th),
 	}
 }
 
-// Next selects the next tab
+// Next selects the next tab that is not hidden
 func (t *Tabs) Next() {
-	t.Tabs[t.Current].Selected = false
-	t.Current = (t.Current + 1) % len(t.Tabs)
-	t.Tabs[t.Current].Selected = true
+	t.move(1)
 }
 
-// Prev selects the previous tab
+// Prev selects the previous tab that is not hidden
 func (t *Tabs) Prev() {
+	t.move(-1)
+}
+
+// move selects the first tab that is not hidden in the given direction, keeping the
+// current tab when every other tab is hidden
+func (t *Tabs) move(step int) {
+	for i := 1; i < len(t.Tabs); i++ {
+		index := (t.Current + step*i + len(t.Tabs)) % len(t.Tabs)
+		if !t.Tabs[index].Hidden {
+			t.Select(index)
+			return
+		}
+	}
+}
+
+// SetHidden hides or shows the tab at the given index. Hiding the current tab
+// selects the next one.
+func (t *Tabs) SetHidden(index int, hidden bool) {
+	if index < 0 || index >= len(t.Tabs) {
+		return
+	}
+	t.Tabs[index].Hidden = hidden
+	if hidden && index == t.Current {
+		t.Next()
+	}
+}
+
+// Select selects the tab at the given index, ignoring out of range indexes
+func (t *Tabs) Select(index int) {
+	if index < 0 || index >= len(t.Tabs) {
+		return
+	}
 	t.Tabs[t.Current].Selected = false
-	t.Current = (t.Current - 1 + len(t.Tabs)) % len(t.Tabs)
+	t.Current = index
 	t.Tabs[t.Current].Selected = true
 }
 
@@ -60,14 +104,17 @@ func (t *Tabs) Prev() {
 func (t Tabs) View() string {
 	var renderedTabs []string
 
-	for i, tab := range t.Tabs {
+	for _, tab := range t.Tabs {
+		if tab.Hidden {
+			continue
+		}
+		if len(renderedTabs) > 0 {
+			renderedTabs = append(renderedTabs, t.styles.gap)
+		}
 		if tab.Selected {
-			renderedTabs = append(renderedTabs, activeTabStyle.Render(tab.Title))
+			renderedTabs = append(renderedTabs, t.styles.active.Render(tab.Title))
 		} else {
-			renderedTabs = append(renderedTabs, inactiveTabStyle.Render(tab.Title))
-		}
-		if i < len(t.Tabs)-1 {
-			renderedTabs = append(renderedTabs, tabGap)
+			renderedTabs = append(renderedTabs, t.styles.inactive.Render(tab.Title))
 		}
 	}
 
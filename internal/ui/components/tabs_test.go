@@ -3,6 +3,8 @@ package components
 import (
 	"strings"
 	"testing"
+
+	"github.com/tnagatomi/gh-portrait/internal/ui/theme"
 )
 
 func TestNewTabs(t *testing.T) {
@@ -28,7 +30,7 @@ func TestNewTabs(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tabs := NewTabs(tt.titles)
+			tabs := NewTabs(tt.titles, theme.DarkTheme())
 
 			// Check number of tabs
 			if got := len(tabs.Tabs); got != tt.wantTabs {
@@ -101,7 +103,7 @@ func TestTabsNext(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tabs := NewTabs(tt.titles)
+			tabs := NewTabs(tt.titles, theme.DarkTheme())
 
 			for range tt.moves {
 				tabs.Next()
@@ -164,7 +166,7 @@ func TestTabsPrev(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tabs := NewTabs(tt.titles)
+			tabs := NewTabs(tt.titles, theme.DarkTheme())
 
 			for range tt.moves {
 				tabs.Prev()
@@ -188,6 +190,52 @@ func TestTabsPrev(t *testing.T) {
 	}
 }
 
+func TestTabsSelect(t *testing.T) {
+	tests := []struct {
+		name        string
+		titles      []string
+		index       int
+		wantCurrent int
+	}{
+		{
+			name:        "select tab",
+			titles:      []string{"Tab 1", "Tab 2", "Tab 3"},
+			index:       2,
+			wantCurrent: 2,
+		},
+		{
+			name:        "out of range index is ignored",
+			titles:      []string{"Tab 1", "Tab 2", "Tab 3"},
+			index:       3,
+			wantCurrent: 0,
+		},
+		{
+			name:        "negative index is ignored",
+			titles:      []string{"Tab 1", "Tab 2", "Tab 3"},
+			index:       -1,
+			wantCurrent: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tabs := NewTabs(tt.titles, theme.DarkTheme())
+			tabs.Select(tt.index)
+
+			if got := tabs.Current; got != tt.wantCurrent {
+				t.Errorf("Select() Current = %v, want %v", got, tt.wantCurrent)
+			}
+
+			// Check only the current tab is selected
+			for i, tab := range tabs.Tabs {
+				if tab.Selected != (i == tt.wantCurrent) {
+					t.Errorf("Select() tab %d selected = %v, want %v", i, tab.Selected, i == tt.wantCurrent)
+				}
+			}
+		})
+	}
+}
+
 func TestTabsView(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -218,7 +266,7 @@ func TestTabsView(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tabs := NewTabs(tt.titles)
+			tabs := NewTabs(tt.titles, theme.DarkTheme())
 			
 			// Move to specified current tab
 			for range tt.current {
@@ -235,7 +283,7 @@ func TestTabsView(t *testing.T) {
 			}
 
 			// Check that there's a gap between tabs (but not after the last tab)
-			gapCount := strings.Count(result, tabGap)
+			gapCount := strings.Count(result, tabs.styles.gap)
 			expectedGaps := len(tt.titles) - 1
 			if gapCount != expectedGaps {
 				t.Errorf("View() gap count = %v, want %v", gapCount, expectedGaps)
@@ -243,7 +291,7 @@ func TestTabsView(t *testing.T) {
 
 			// Check active tab style
 			activeTab := tt.titles[tt.current]
-			styledActiveTab := activeTabStyle.Render(activeTab)
+			styledActiveTab := tabs.styles.active.Render(activeTab)
 			if !strings.Contains(result, styledActiveTab) {
 				t.Errorf("View() active tab style not applied correctly for %q", activeTab)
 			}
@@ -251,7 +299,7 @@ func TestTabsView(t *testing.T) {
 			// Check inactive tab style
 			for i, title := range tt.titles {
 				if i != tt.current {
-					styledInactiveTab := inactiveTabStyle.Render(title)
+					styledInactiveTab := tabs.styles.inactive.Render(title)
 					if !strings.Contains(result, styledInactiveTab) {
 						t.Errorf("View() inactive tab style not applied correctly for %q", title)
 					}
@@ -260,3 +308,51 @@ func TestTabsView(t *testing.T) {
 		})
 	}
 }
+
+func TestTabsHidden(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     int
+		hidden      int
+		move        func(tabs *Tabs)
+		wantCurrent int
+	}{
+		{
+			name:        "hiding the current tab selects the next one",
+			current:     0,
+			hidden:      0,
+			move:        func(tabs *Tabs) {},
+			wantCurrent: 1,
+		},
+		{
+			name:        "next skips a hidden tab",
+			current:     0,
+			hidden:      1,
+			move:        func(tabs *Tabs) { tabs.Next() },
+			wantCurrent: 2,
+		},
+		{
+			name:        "prev skips a hidden tab when wrapping around",
+			current:     1,
+			hidden:      0,
+			move:        func(tabs *Tabs) { tabs.Prev() },
+			wantCurrent: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tabs := NewTabs([]string{"Tab 1", "Tab 2", "Tab 3"}, theme.DarkTheme())
+			tabs.Select(tt.current)
+			tabs.SetHidden(tt.hidden, true)
+			tt.move(&tabs)
+
+			if got := tabs.Current; got != tt.wantCurrent {
+				t.Errorf("Current = %v, want %v", got, tt.wantCurrent)
+			}
+			if got := tabs.View(); strings.Contains(got, tabs.Tabs[tt.hidden].Title) {
+				t.Errorf("View() = %q, should not contain hidden tab %q", got, tabs.Tabs[tt.hidden].Title)
+			}
+		})
+	}
+}
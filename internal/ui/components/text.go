@@ -0,0 +1,118 @@
+package components
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/yuin/goldmark-emoji/definition"
+)
+
+var (
+	// shortcodePattern matches emoji shortcodes such as :rocket:
+	shortcodePattern = regexp.MustCompile(`:([a-z0-9_+-]+):`)
+
+	// urlPattern matches URLs including trailing punctuation, which trimURL leaves out
+	urlPattern = regexp.MustCompile(`https?://[^\s<>"']+`)
+
+	// referencePattern matches @mentions of users and teams, and issue references
+	// such as #12 or owner/repo#12, that do not follow a word character
+	referencePattern = regexp.MustCompile(`(?:^|[^\w/@#])(@[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?(?:/[\w.-]*\w)?|(?:[A-Za-z0-9-]+/[\w.-]+)?#\d+)\b`)
+
+	// emojis holds the emoji shortcodes supported by GitHub
+	emojis = definition.Github()
+
+	// openableSchemes are the URL schemes of links opened in the browser
+	openableSchemes = []string{"http", "https", "mailto"}
+)
+
+// IsOpenableURL reports whether the URL is a web or email link. Only these are
+// opened in the browser or turned into hyperlinks.
+func IsOpenableURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && slices.Contains(openableSchemes, strings.ToLower(u.Scheme))
+}
+
+// expandEmoji replaces GitHub emoji shortcodes with their emoji, leaving unknown
+// shortcodes as they are
+func expandEmoji(s string) string {
+	if !strings.Contains(s, ":") {
+		return s
+	}
+	return shortcodePattern.ReplaceAllStringFunc(s, func(shortcode string) string {
+		emoji, ok := emojis.Get(strings.Trim(shortcode, ":"))
+		if !ok || !emoji.IsUnicode() {
+			return shortcode
+		}
+		return string(emoji.Unicode)
+	})
+}
+
+// reference is a URL, @mention or issue reference found in text
+type reference struct {
+	start, end int    // Byte offsets of the reference in the text
+	url        string // Empty for issue references without a repository
+}
+
+// findReferences finds the URLs, @mentions and issue references in the text, in
+// order. References inside URLs are left out.
+func findReferences(s string) []reference {
+	var refs []reference
+	for _, match := range urlPattern.FindAllStringIndex(s, -1) {
+		url := trimURL(s[match[0]:match[1]])
+		refs = append(refs, reference{start: match[0], end: match[0] + len(url), url: url})
+	}
+	urls := len(refs)
+
+	for _, match := range referencePattern.FindAllStringSubmatchIndex(s, -1) {
+		ref := reference{start: match[2], end: match[3]}
+		inURL := false
+		for _, url := range refs[:urls] {
+			inURL = inURL || (ref.start < url.end && ref.end > url.start)
+		}
+		if inURL {
+			continue
+		}
+		ref.url = referenceURL(s[ref.start:ref.end])
+		refs = append(refs, ref)
+	}
+
+	slices.SortFunc(refs, func(a, b reference) int {
+		return a.start - b.start
+	})
+	return refs
+}
+
+// trimURL leaves out the trailing punctuation of a URL, and closing parentheses
+// without an opening one in the URL, such as the one of a URL in parentheses
+func trimURL(url string) string {
+	for url != "" {
+		last := url[len(url)-1]
+		switch {
+		case strings.IndexByte(".,;:!?", last) >= 0:
+		case last == ')' && strings.Count(url, ")") > strings.Count(url, "("):
+		default:
+			return url
+		}
+		url = url[:len(url)-1]
+	}
+	return url
+}
+
+// referenceURL returns the GitHub URL of an @mention or issue reference, or an
+// empty string for issue references without a repository
+func referenceURL(ref string) string {
+	if login, ok := strings.CutPrefix(ref, "@"); ok {
+		if org, team, ok := strings.Cut(login, "/"); ok {
+			return "https://github.com/orgs/" + org + "/teams/" + team
+		}
+		return "https://github.com/" + login
+	}
+
+	repo, number, _ := strings.Cut(ref, "#")
+	if repo == "" {
+		return ""
+	}
+	return "https://github.com/" + repo + "/issues/" + number
+}
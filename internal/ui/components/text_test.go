@@ -0,0 +1,131 @@
+package components
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExpandEmoji(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{
+			name: "shortcode",
+			s:    "Shipping :rocket: fast",
+			want: "Shipping 🚀 fast",
+		},
+		{
+			name: "adjacent shortcodes",
+			s:    ":+1::tada:",
+			want: "👍🎉",
+		},
+		{
+			name: "unknown shortcode",
+			s:    "Ratio :not_an_emoji: here",
+			want: "Ratio :not_an_emoji: here",
+		},
+		{
+			name: "time is not a shortcode",
+			s:    "Meeting at 10:30:00",
+			want: "Meeting at 10:30:00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandEmoji(tt.s); got != tt.want {
+				t.Errorf("expandEmoji() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindReferences(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want []string // Text and URL of each reference
+	}{
+		{
+			name: "mention",
+			s:    "Working at @github",
+			want: []string{"@github", "https://github.com/github"},
+		},
+		{
+			name: "team mention and issue references",
+			s:    "@cli/maintainers fixed #12 and cli/cli#34.",
+			want: []string{
+				"@cli/maintainers", "https://github.com/orgs/cli/teams/maintainers",
+				"#12", "",
+				"cli/cli#34", "https://github.com/cli/cli/issues/34",
+			},
+		},
+		{
+			name: "URL before a mention",
+			s:    "Blog (https://example.com/posts/), ex-@octo-org",
+			want: []string{
+				"https://example.com/posts/", "https://example.com/posts/",
+				"@octo-org", "https://github.com/octo-org",
+			},
+		},
+		{
+			name: "URL with parentheses",
+			s:    "Go (https://en.wikipedia.org/wiki/Go_(programming_language)).",
+			want: []string{
+				"https://en.wikipedia.org/wiki/Go_(programming_language)",
+				"https://en.wikipedia.org/wiki/Go_(programming_language)",
+			},
+		},
+		{
+			name: "URL ending a sentence in parentheses",
+			s:    "(see https://example.com/a.)",
+			want: []string{"https://example.com/a", "https://example.com/a"},
+		},
+		{
+			name: "email address is not a mention",
+			s:    "Mail octocat@example.com",
+		},
+		{
+			name: "references inside a URL are left out",
+			s:    "https://example.com/@octocat/#1",
+			want: []string{"https://example.com/@octocat/#1", "https://example.com/@octocat/#1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, ref := range findReferences(tt.s) {
+				got = append(got, tt.s[ref.start:ref.end], ref.url)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("findReferences() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsOpenableURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{url: "https://example.com", want: true},
+		{url: "HTTP://example.com", want: true},
+		{url: "mailto:octocat@example.com", want: true},
+		{url: "javascript:alert(1)", want: false},
+		{url: "file:///etc/passwd", want: false},
+		{url: "example.com", want: false},
+		{url: "%zz", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := IsOpenableURL(tt.url); got != tt.want {
+				t.Errorf("IsOpenableURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
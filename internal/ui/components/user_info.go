@@ -2,65 +2,499 @@ package components
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/tnagatomi/gh-portrait/internal/github"
+	"github.com/tnagatomi/gh-portrait/internal/termimage"
+	"github.com/tnagatomi/gh-portrait/internal/ui/theme"
 )
 
-var (
-	userInfoTitleStyle = lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("86"))
+const (
+	// maxCachedREADMEs is the number of widths the rendered README is cached for
+	maxCachedREADMEs = 8
+
+	// minInfoWidth is the width left for the Info section when the avatar is shown
+	minInfoWidth = 40
 )
 
-// UserInfo represents the user information view
+// READMERenderedMsg is sent when the README has been rendered in the background
+type READMERenderedMsg struct {
+	width      int
+	generation int
+	readme     string
+}
+
+// Link is a link shown in the user information
+type Link struct {
+	Text string
+	URL  string
+	Line int // Line of the view the link is on
+}
+
+// UserInfo represents the user information view. The view is rendered again only
+// after the user, the width or the rendered README changes. READMEs are rendered
+// in the background with RenderREADME and cached for each width.
 type UserInfo struct {
-	user           *github.User
-	renderer       MarkdownRenderer
-	cachedREADME   string
-	viewWidth      int
-	readmeRendered bool
+	user         *github.User
+	renderer     MarkdownRenderer
+	readmes      map[int]string // Rendered README by width
+	latestREADME string         // README last rendered, shown until it is rendered for the width
+	generation   int            // Number of the user, to ignore READMEs rendered for a previous one
+	cachedView   string
+	viewWidth    int
+	dirty        bool                       // Whether cachedView is out of date
+	avatars      map[string]string          // Rendered avatars of the user and organizations by URL
+	protocol     termimage.Protocol         // Protocol drawing README images, or none
+	images       map[string]downloadedImage // Downloaded README images by URL
+	imageViews   map[string]string          // README images drawn for the view width by URL, empty when too small
+	released     string                     // Escape sequences deleting the replaced images from the terminal
+	links        []Link                     // Links in the order they are shown, collected by render
+	markdown     string                     // README with its relative URLs resolved
+	outline      readmeOutline              // Links and headings of the README markdown
+	headings     []Heading                  // Headings of the rendered README, located by render
+	selected     int                        // Index of the selected link, -1 when none is
+	hyperlinks   bool                       // Whether links are emitted as OSC 8 hyperlinks
+	titleStyle   lipgloss.Style
+	badgeStyle   lipgloss.Style
+	linkStyle    lipgloss.Style
+	selectedLink lipgloss.Style
+	subtleStyle  lipgloss.Style
+	query        string  // Text searched for, empty when there is no search
+	matches      []Match // Matches of the query in the view, found by View
+	match        int     // Index of the current match
+	matchStyle   lipgloss.Style
+	currentMatch lipgloss.Style
 }
 
 // NewUserInfo creates a new UserInfo instance
-func NewUserInfo(user *github.User, renderer MarkdownRenderer) UserInfo {
-	return UserInfo{
-		user:           user,
-		renderer:       renderer,
-		viewWidth:      80, // Default width
-		readmeRendered: false,
+func NewUserInfo(user *github.User, renderer MarkdownRenderer, th theme.Theme) UserInfo {
+	u := UserInfo{
+		user:       user,
+		renderer:   renderer,
+		readmes:    make(map[int]string),
+		avatars:    make(map[string]string),
+		protocol:   termimage.None,
+		images:     make(map[string]downloadedImage),
+		imageViews: make(map[string]string),
+		selected:   -1,
+		viewWidth:  80, // Default width
+		dirty:      true,
+		titleStyle: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(th.Accent),
+		badgeStyle: lipgloss.NewStyle().
+			Foreground(th.Accent),
+		linkStyle: lipgloss.NewStyle().
+			Foreground(th.Accent).
+			Underline(true),
+		selectedLink: lipgloss.NewStyle().
+			Foreground(th.Accent).
+			Underline(true).
+			Reverse(true),
+		subtleStyle: lipgloss.NewStyle().
+			Foreground(th.Subtle),
+		matchStyle: lipgloss.NewStyle().
+			Reverse(true),
+		currentMatch: lipgloss.NewStyle().
+			Foreground(th.Accent).
+			Bold(true).
+			Reverse(true),
 	}
+	u.loadREADME()
+	return u
 }
 
-// SetWidth updates the view width and triggers README re-rendering if needed
+// SetUser replaces the user shown. Its README is rendered with RenderREADME.
+func (u *UserInfo) SetUser(user *github.User) {
+	// Keep the selected link when the user is fetched again
+	if u.user == nil || user == nil || u.user.Login != user.Login {
+		u.selected = -1
+	}
+	u.user = user
+	// Keep the avatars still shown when the user is fetched again
+	for url, avatar := range u.avatars {
+		if !u.showsAvatar(url) {
+			u.release(avatar)
+			delete(u.avatars, url)
+		}
+	}
+	u.loadREADME()
+	// Keep the README images still shown too
+	for url := range u.images {
+		if !u.showsImage(url) {
+			u.release(u.imageViews[url])
+			delete(u.images, url)
+			delete(u.imageViews, url)
+		}
+	}
+	u.readmes = make(map[int]string)
+	u.latestREADME = ""
+	u.generation++
+	u.dirty = true
+}
+
+// loadREADME converts the HTML of the user's README to markdown, resolves its
+// relative URLs against their profile repository and parses its links and headings
+func (u *UserInfo) loadREADME() {
+	u.markdown, u.outline = "", readmeOutline{}
+	if u.user == nil || u.user.README == nil {
+		return
+	}
+	u.markdown = resolveREADMEURLs(htmlToMarkdown(*u.user.README), u.user.Login, u.user.READMEBranch)
+	u.outline = parseREADME(u.markdown)
+}
+
+// SetWidth updates the view width. The README and its images are drawn for the new
+// width with RenderREADME and DrawREADMEImages.
 func (u *UserInfo) SetWidth(width int) {
 	if u.viewWidth != width {
 		u.viewWidth = width
-		u.readmeRendered = false // Force re-render on width change
+		u.releaseImageViews()
+		u.dirty = true
 	}
 }
 
-// renderREADME renders the README content with the current width
-func (u *UserInfo) renderREADME() {
-	if u.user.README == nil {
-		u.cachedREADME = ""
+// RenderREADME returns a command rendering the README for the current width in the
+// background, or nil when there is no README or it is already rendered
+func (u *UserInfo) RenderREADME() tea.Cmd {
+	if u.user == nil || u.user.README == nil {
+		return nil
+	}
+	if _, ok := u.readmes[u.viewWidth]; ok {
+		return nil
+	}
+
+	renderer, readme := u.renderer, u.markdown
+	width, generation := u.viewWidth, u.generation
+	return func() tea.Msg {
+		return READMERenderedMsg{
+			width:      width,
+			generation: generation,
+			readme:     renderer.Render(readme, width),
+		}
+	}
+}
+
+// SetREADME stores a README rendered by RenderREADME
+func (u *UserInfo) SetREADME(msg READMERenderedMsg) {
+	if msg.generation != u.generation {
+		return
+	}
+	if len(u.readmes) >= maxCachedREADMEs {
+		u.readmes = make(map[int]string)
+	}
+	u.readmes[msg.width] = msg.readme
+	u.latestREADME = msg.readme
+	u.dirty = true
+}
+
+// release deletes the images drawn in the view from the terminal with the next frame
+func (u *UserInfo) release(view string) {
+	u.released += termimage.Release(view)
+}
+
+// TakeReleasedImages returns the escape sequences deleting the images replaced since
+// the last call from the terminal, which are written with the next frame
+func (u *UserInfo) TakeReleasedImages() string {
+	released := u.released
+	u.released = ""
+	return released
+}
+
+// SetAvatar shows the avatar of the user or of one of their organizations
+// downloaded from the URL, ignoring avatars of previous users
+func (u *UserInfo) SetAvatar(url, avatar string) {
+	if !u.showsAvatar(url) {
 		return
 	}
+	u.avatars[url] = avatar
+	u.dirty = true
+}
 
-	u.cachedREADME = u.renderer.Render(*u.user.README, u.viewWidth)
-	u.readmeRendered = true
+// HasAvatar reports whether the avatar downloaded from the URL is shown
+func (u *UserInfo) HasAvatar(url string) bool {
+	_, ok := u.avatars[url]
+	return ok
 }
 
-// View renders the user information
+// showsAvatar reports whether the URL is the avatar of the user or of one of their
+// organizations
+func (u *UserInfo) showsAvatar(url string) bool {
+	if u.user == nil {
+		return false
+	}
+	if u.user.AvatarURL == url {
+		return true
+	}
+	for _, org := range u.user.Organizations {
+		if org.AvatarURL == url {
+			return true
+		}
+	}
+	return false
+}
+
+// SetHyperlinks sets whether links are emitted as OSC 8 hyperlinks, which terminals
+// supporting them make clickable
+func (u *UserInfo) SetHyperlinks(enabled bool) {
+	if u.hyperlinks != enabled {
+		u.hyperlinks = enabled
+		u.dirty = true
+	}
+}
+
+// NextLink selects the next link, wrapping around
+func (u *UserInfo) NextLink() {
+	if len(u.links) == 0 {
+		return
+	}
+	u.selected = (u.selected + 1) % len(u.links)
+	u.dirty = true
+}
+
+// PrevLink selects the previous link, wrapping around
+func (u *UserInfo) PrevLink() {
+	if len(u.links) == 0 {
+		return
+	}
+	if u.selected <= 0 {
+		u.selected = len(u.links)
+	}
+	u.selected--
+	u.dirty = true
+}
+
+// SelectedLink returns the selected link, if any
+func (u *UserInfo) SelectedLink() (Link, bool) {
+	if u.selected < 0 || u.selected >= len(u.links) {
+		return Link{}, false
+	}
+	return u.links[u.selected], true
+}
+
+// Headings returns the headings of the README with the lines they are on, as of
+// the last View. There are none until the README is rendered.
+func (u *UserInfo) Headings() []Heading {
+	return u.headings
+}
+
+// link renders a link on the given line of the view, highlighted when it is
+// selected, and records it for selection. Links that cannot be opened are shown
+// as plain text.
+func (u *UserInfo) link(text, url string, line int) string {
+	if !IsOpenableURL(url) {
+		return text
+	}
+	selected := len(u.links) == u.selected
+	u.links = append(u.links, Link{Text: text, URL: url, Line: line})
+	return u.linkView(text, url, selected)
+}
+
+// linkView renders a link, highlighted when it is selected
+func (u *UserInfo) linkView(text, url string, selected bool) string {
+	style := u.linkStyle
+	if selected {
+		style = u.selectedLink
+	}
+	text = style.Render(text)
+	if u.hyperlinks {
+		text = ansi.SetHyperlink(url) + text + ansi.ResetHyperlink()
+	}
+	return text
+}
+
+// websiteURL adds the scheme missing from websites entered without one
+func websiteURL(website string) string {
+	if strings.Contains(website, "://") {
+		return website
+	}
+	return "https://" + website
+}
+
+// readme returns the README rendered for the current width, or the one rendered
+// last while it is rendered for the width. ok is false while no README is rendered.
+func (u *UserInfo) readme() (readme string, ok bool) {
+	if readme, ok := u.readmes[u.viewWidth]; ok {
+		return readme, true
+	}
+	if u.latestREADME != "" {
+		return u.latestREADME, true
+	}
+	return "  Rendering README...\n", false
+}
+
+// readmeView returns the rendered README starting at the given line of the view,
+// recording its links and headings. The line of the selected README link is marked.
+func (u *UserInfo) readmeView(start int) string {
+	u.headings = nil
+	readme, ok := u.readme()
+	if !ok {
+		return readme
+	}
+
+	readme, placeholders := u.withImages(readme)
+	outline := u.outline.locate(readme, start)
+	u.headings = outline.headings
+	first := len(u.links)
+	u.links = append(u.links, outline.links...)
+	for _, link := range placeholders {
+		link.Line += start
+		u.links = append(u.links, link)
+	}
+	slices.SortStableFunc(u.links[first:], func(a, b Link) int {
+		return a.Line - b.Line
+	})
+	if u.selected < first || u.selected >= len(u.links) {
+		return readme
+	}
+
+	lines := strings.Split(readme, "\n")
+	if i := u.links[u.selected].Line - start; i < len(lines) {
+		lines[i] = u.badgeStyle.Render("▶") + ansi.TruncateLeft(lines[i], 1, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Dirty reports whether the view changed since it was last rendered
+func (u *UserInfo) Dirty() bool {
+	return u.dirty
+}
+
+// View returns the user information, rendering it again if it is dirty
 func (u *UserInfo) View() string {
+	if u.dirty {
+		u.cachedView = u.render()
+		u.dirty = false
+		// The links changed with the user
+		if u.selected >= len(u.links) {
+			u.selected = -1
+		}
+
+		u.matches = findMatches(u.cachedView, u.query)
+		if u.match >= len(u.matches) {
+			u.match = 0
+		}
+		u.cachedView = highlightMatches(u.cachedView, u.matches, u.match, u.matchStyle, u.currentMatch)
+	}
+	return u.cachedView
+}
+
+// statusView renders the user's status on the given line, or an empty string when
+// it is not set
+func (u *UserInfo) statusView(line int) string {
+	status := u.user.Status
+	if status == nil {
+		return ""
+	}
+
+	parts := make([]string, 0, 3)
+	if status.Emoji != "" {
+		parts = append(parts, expandEmoji(status.Emoji))
+	}
+	if status.Message != "" {
+		parts = append(parts, u.richText(status.Message, line))
+	}
+	if status.Busy {
+		parts = append(parts, u.badgeStyle.Render("(busy)"))
+	}
+	return strings.Join(parts, " ")
+}
+
+// richText expands emoji shortcodes in text written by the user on the given line,
+// and turns its URLs, @mentions and issue references into links
+func (u *UserInfo) richText(s string, line int) string {
+	s = expandEmoji(s)
+
+	var b strings.Builder
+	last := 0
+	for _, ref := range findReferences(s) {
+		b.WriteString(s[last:ref.start])
+		if text := s[ref.start:ref.end]; ref.url == "" {
+			b.WriteString(u.linkStyle.Render(text))
+		} else {
+			b.WriteString(u.link(text, ref.url, line))
+		}
+		last = ref.end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// badgesView renders the badges describing the user and their relationship with
+// the authenticated user
+func (u *UserInfo) badgesView() string {
+	var badges []string
+	if u.user.IsViewer {
+		badges = append(badges, "you")
+	}
+	if u.user.IsFollowingViewer {
+		badges = append(badges, "follows you")
+	}
+	if u.user.ViewerIsFollowing {
+		badges = append(badges, "you follow")
+	}
+	if u.user.IsHireable {
+		badges = append(badges, "available for hire")
+	}
+	if u.user.HasSponsorsListing {
+		badges = append(badges, "sponsorable")
+	}
+
+	for i, badge := range badges {
+		badges[i] = u.badgeStyle.Render("[" + badge + "]")
+	}
+	return strings.Join(badges, " ")
+}
+
+// accountAge describes how long ago the account was created, in the largest whole
+// unit
+func accountAge(created, now time.Time) string {
+	years, months, days := now.Year()-created.Year(), int(now.Month()-created.Month()), now.Day()-created.Day()
+	if days < 0 {
+		months--
+	}
+	if months < 0 {
+		years--
+		months += 12
+	}
+
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s", unit)
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+	switch {
+	case years > 0:
+		return plural(years, "year")
+	case months > 0:
+		return plural(months, "month")
+	case now.Sub(created) >= 24*time.Hour:
+		return plural(int(now.Sub(created).Hours()/24), "day")
+	default:
+		return "today"
+	}
+}
+
+// render renders the user information
+func (u *UserInfo) render() string {
 	var content string
+	u.links = u.links[:0]
+	// line returns the line of the view the next line of content is on
+	line := func() int {
+		return strings.Count(content, "\n")
+	}
 
 	// Info section
-	content += userInfoTitleStyle.Render("  Info") + "\n"
+	content += u.titleStyle.Render("  Info") + "\n"
 	content += "  Name: " + u.user.Name + "\n"
 	if u.user.Bio != "" {
-		content += "  Bio: " + u.user.Bio + "\n"
+		content += "  Bio: " + u.richText(u.user.Bio, line()) + "\n"
 	}
 	if u.user.Pronouns != "" {
 		content += "  Pronouns: " + u.user.Pronouns + "\n"
@@ -72,17 +506,63 @@ func (u *UserInfo) View() string {
 		content += "  Location: " + u.user.Location + "\n"
 	}
 	if u.user.WebsiteURL != "" {
-		content += "  Website: " + u.user.WebsiteURL + "\n"
+		content += "  Website: " + u.link(u.user.WebsiteURL, websiteURL(u.user.WebsiteURL), line()) + "\n"
+	}
+	if u.user.Email != "" {
+		content += "  Email: " + u.link(u.user.Email, "mailto:"+u.user.Email, line()) + "\n"
+	}
+	if u.user.TwitterUsername != "" {
+		content += "  Twitter: " + u.link("@"+u.user.TwitterUsername, "https://x.com/"+u.user.TwitterUsername, line()) + "\n"
+	}
+	if !u.user.CreatedAt.IsZero() {
+		content += fmt.Sprintf("  Joined: %s (%s)\n",
+			u.user.CreatedAt.Format("Jan 2, 2006"),
+			accountAge(u.user.CreatedAt, time.Now()),
+		)
+	}
+	if status := u.statusView(line()); status != "" {
+		content += "  Status: " + status + "\n"
+	}
+	if badges := u.badgesView(); badges != "" {
+		content += "  " + badges + "\n"
+	}
+
+	// Avatar to the left of the Info section, when there is room for both
+	if avatar, ok := u.avatars[u.user.AvatarURL]; ok && u.viewWidth >= lipgloss.Width(avatar)+minInfoWidth {
+		avatar = "  " + strings.ReplaceAll(avatar, "\n", "\n  ")
+		content = lipgloss.JoinHorizontal(lipgloss.Top, avatar, strings.TrimSuffix(content, "\n")) + "\n"
 	}
 	content += "\n"
 
+	// Organizations section
+	if len(u.user.Organizations) > 0 {
+		content += u.titleStyle.Render("  Organizations") + "\n"
+		for _, org := range u.user.Organizations {
+			name := org.Login
+			if org.Name != "" {
+				name = org.Name
+			}
+			name = u.link(name, "https://github.com/"+org.Login, line())
+			if org.Name != "" {
+				name += " " + u.subtleStyle.Render("@"+org.Login)
+			}
+			if avatar, ok := u.avatars[org.AvatarURL]; ok {
+				avatar = "  " + strings.ReplaceAll(avatar, "\n", "\n  ")
+				content += lipgloss.JoinHorizontal(lipgloss.Center, avatar, " "+name) + "\n"
+			} else {
+				content += "  " + name + "\n"
+			}
+		}
+		content += "\n"
+	}
+
 	// Social accounts section
 	if len(u.user.Social) > 0 {
-		content += userInfoTitleStyle.Render("  Social accounts") + "\n"
+		content += u.titleStyle.Render("  Social accounts") + "\n"
 		for _, account := range u.user.Social {
 			content += fmt.Sprintf("  %s: %s\n",
 				account.Provider,
-				account.URL,
+				u.link(account.URL, account.URL, line()),
 			)
 		}
 		content += "\n"
@@ -90,14 +570,10 @@ func (u *UserInfo) View() string {
 
 	// README section
 	if u.user.README != nil {
-		if !u.readmeRendered {
-			u.renderREADME()
-		}
-
 		// Create a divider line using box-drawing characters
 		divider := "  " + strings.Repeat("─", 50) + "\n\n"
 		content += divider
-		content += u.cachedREADME
+		content += u.readmeView(line())
 	}
 
 	return content
@@ -1,10 +1,15 @@
 package components
 
 import (
+	"fmt"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/tnagatomi/gh-portrait/internal/github"
+	"github.com/tnagatomi/gh-portrait/internal/ui/theme"
 )
 
 // stringPtr returns a pointer to the given string
@@ -12,6 +17,31 @@ func stringPtr(s string) *string {
 	return &s
 }
 
+// renderREADME renders the README as the application does, running the command
+// returned by RenderREADME and passing its message to SetREADME
+func renderREADME(ui *UserInfo) {
+	if cmd := ui.RenderREADME(); cmd != nil {
+		ui.SetREADME(cmd().(READMERenderedMsg))
+	}
+}
+
+// drawREADMEImage draws README images as the application does, running the command
+// returned by SetREADMEImage or DrawREADMEImages and passing its messages to
+// SetDrawnREADMEImage
+func drawREADMEImage(ui *UserInfo, cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			drawREADMEImage(ui, cmd)
+		}
+	case READMEImageDrawnMsg:
+		ui.SetDrawnREADMEImage(msg)
+	}
+}
+
 func TestUserInfoView(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -136,6 +166,45 @@ func TestUserInfoView(t *testing.T) {
 				"Website:",
 			},
 		},
+		{
+			name: "user with profile details",
+			user: &github.User{
+				Name:               "Takayuki Nagatomi",
+				Bio:                "Building :rocket: at @github",
+				Email:              "octocat@example.com",
+				TwitterUsername:    "tnagatomi",
+				CreatedAt:          time.Date(2010, time.March, 4, 0, 0, 0, 0, time.UTC),
+				IsHireable:         true,
+				HasSponsorsListing: true,
+				IsFollowingViewer:  true,
+				Status: &github.UserStatus{
+					Emoji:   ":palm_tree:",
+					Message: "On vacation",
+					Busy:    true,
+				},
+				Organizations: []github.Organization{
+					{Login: "cli", Name: "GitHub CLI"},
+					{Login: "charmbracelet"},
+				},
+			},
+			want: []string{
+				"Email: octocat@example.com",
+				"Twitter: @tnagatomi",
+				"Joined: Mar 4, 2010",
+				"Status: 🌴 On vacation (busy)",
+				"Bio: Building 🚀 at @github",
+				"[follows you]",
+				"[available for hire]",
+				"[sponsorable]",
+				"Organizations",
+				"GitHub CLI @cli",
+				"  charmbracelet",
+			},
+			notWant: []string{
+				"[you follow]",
+				"[you]",
+			},
+		},
 		{
 			name: "user with empty fields",
 			user: &github.User{
@@ -156,6 +225,12 @@ func TestUserInfoView(t *testing.T) {
 				"Company:",
 				"Location:",
 				"Website:",
+				"Email:",
+				"Twitter:",
+				"Joined:",
+				"Status:",
+				"[",
+				"Organizations",
 				"Social accounts",
 			},
 		},
@@ -164,10 +239,11 @@ func TestUserInfoView(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			testRenderer := NewTestRenderer()
-			ui := NewUserInfo(tt.user, testRenderer)
+			ui := NewUserInfo(tt.user, testRenderer, theme.DarkTheme())
 			if tt.setWidth {
 				ui.SetWidth(tt.width)
 			}
+			renderREADME(&ui)
 			got := ui.View()
 
 			// Remove ANSI escape sequences for comparison
@@ -189,11 +265,11 @@ func TestUserInfoView(t *testing.T) {
 			}
 
 			// Verify styling
-			if !strings.Contains(got, userInfoTitleStyle.Render("Info")) {
+			if !strings.Contains(got, ui.titleStyle.Render("Info")) {
 				t.Error("UserInfo.View() does not contain styled Info title")
 			}
 
-			if len(tt.user.Social) > 0 && !strings.Contains(got, userInfoTitleStyle.Render("Social accounts")) {
+			if len(tt.user.Social) > 0 && !strings.Contains(got, ui.titleStyle.Render("Social accounts")) {
 				t.Error("UserInfo.View() does not contain styled Social accounts title")
 			}
 
@@ -207,6 +283,7 @@ func TestUserInfoView(t *testing.T) {
 
 				// Change width and verify content changes
 				ui.SetWidth(tt.width + 10)
+				renderREADME(&ui)
 				thirdView := ui.View()
 				if thirdView == got {
 					t.Error("UserInfo.View() content did not change after width change")
@@ -215,3 +292,373 @@ func TestUserInfoView(t *testing.T) {
 		})
 	}
 }
+
+// countingRenderer counts the markdown renders
+type countingRenderer struct {
+	MarkdownRenderer
+	renders int
+}
+
+// Render renders markdown content, counting the render
+func (r *countingRenderer) Render(markdown string, width int) string {
+	r.renders++
+	return r.MarkdownRenderer.Render(markdown, width)
+}
+
+func TestUserInfoDirty(t *testing.T) {
+	user := &github.User{
+		Name:   "Takayuki Nagatomi",
+		README: stringPtr("# Test README"),
+	}
+
+	tests := []struct {
+		name        string
+		update      func(ui *UserInfo)
+		wantDirty   bool
+		wantRenders int
+	}{
+		{
+			name:        "no change",
+			update:      func(ui *UserInfo) {},
+			wantDirty:   false,
+			wantRenders: 1,
+		},
+		{
+			name:        "same width",
+			update:      func(ui *UserInfo) { ui.SetWidth(80) },
+			wantDirty:   false,
+			wantRenders: 1,
+		},
+		{
+			name:        "width change",
+			update:      func(ui *UserInfo) { ui.SetWidth(60) },
+			wantDirty:   true,
+			wantRenders: 2,
+		},
+		{
+			name: "width change back",
+			update: func(ui *UserInfo) {
+				ui.SetWidth(60)
+				renderREADME(ui)
+				ui.View()
+				ui.SetWidth(80)
+			},
+			wantDirty:   true,
+			wantRenders: 2,
+		},
+		{
+			name:        "user change",
+			update:      func(ui *UserInfo) { ui.SetUser(&github.User{Name: "Someone", README: stringPtr("# README")}) },
+			wantDirty:   true,
+			wantRenders: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer := &countingRenderer{MarkdownRenderer: NewTestRenderer()}
+			ui := NewUserInfo(user, renderer, theme.DarkTheme())
+			renderREADME(&ui)
+			ui.View()
+
+			tt.update(&ui)
+			if got := ui.Dirty(); got != tt.wantDirty {
+				t.Errorf("Dirty() = %v, want %v", got, tt.wantDirty)
+			}
+
+			// READMEs already rendered for the width are not rendered again
+			renderREADME(&ui)
+			ui.View()
+			ui.View()
+			if ui.Dirty() {
+				t.Error("Dirty() = true after View(), want false")
+			}
+			if renderer.renders != tt.wantRenders {
+				t.Errorf("README renders = %v, want %v", renderer.renders, tt.wantRenders)
+			}
+		})
+	}
+}
+
+func TestUserInfoREADMEPlaceholder(t *testing.T) {
+	user := &github.User{
+		Name:   "Takayuki Nagatomi",
+		README: stringPtr("# Test README"),
+	}
+	ui := NewUserInfo(user, NewTestRenderer(), theme.DarkTheme())
+
+	if got := ui.View(); !strings.Contains(got, "Rendering README...") {
+		t.Errorf("UserInfo.View() = %v, want substring %v", got, "Rendering README...")
+	}
+
+	// The README rendered for the previous width is shown until it is rendered again
+	renderREADME(&ui)
+	ui.SetWidth(60)
+	got := ui.View()
+	if strings.Contains(got, "Rendering README...") || !strings.Contains(got, "Test README") {
+		t.Errorf("UserInfo.View() = %v, want the README rendered for the previous width", got)
+	}
+}
+
+func TestUserInfoSetREADMEStale(t *testing.T) {
+	ui := NewUserInfo(&github.User{
+		Name:   "Takayuki Nagatomi",
+		README: stringPtr("# Old README"),
+	}, NewTestRenderer(), theme.DarkTheme())
+	cmd := ui.RenderREADME()
+
+	// The user changes while the README of the previous one is rendered
+	ui.SetUser(&github.User{Name: "Someone", README: stringPtr("# New README")})
+	ui.SetREADME(cmd().(READMERenderedMsg))
+
+	got := ui.View()
+	if strings.Contains(got, "Old README") {
+		t.Errorf("UserInfo.View() = %v, should not contain substring %v", got, "Old README")
+	}
+	if !strings.Contains(got, "Rendering README...") {
+		t.Errorf("UserInfo.View() = %v, want substring %v", got, "Rendering README...")
+	}
+}
+
+func TestUserInfoAvatar(t *testing.T) {
+	const url = "https://avatars.githubusercontent.com/u/1"
+	avatar := "AVATAR\nAVATAR"
+
+	tests := []struct {
+		name   string
+		update func(ui *UserInfo)
+		want   bool // Whether the avatar is shown
+	}{
+		{
+			name:   "avatar of the user",
+			update: func(ui *UserInfo) { ui.SetAvatar(url, avatar) },
+			want:   true,
+		},
+		{
+			name:   "avatar of a previous user",
+			update: func(ui *UserInfo) { ui.SetAvatar("https://avatars.githubusercontent.com/u/2", avatar) },
+			want:   false,
+		},
+		{
+			name: "user fetched again",
+			update: func(ui *UserInfo) {
+				ui.SetAvatar(url, avatar)
+				ui.SetUser(&github.User{Name: "Takayuki Nagatomi", AvatarURL: url})
+			},
+			want: true,
+		},
+		{
+			name: "user with another avatar",
+			update: func(ui *UserInfo) {
+				ui.SetAvatar(url, avatar)
+				ui.SetUser(&github.User{Name: "Takayuki Nagatomi", AvatarURL: "https://avatars.githubusercontent.com/u/2"})
+			},
+			want: false,
+		},
+		{
+			name: "too narrow",
+			update: func(ui *UserInfo) {
+				ui.SetAvatar(url, avatar)
+				ui.SetWidth(40)
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ui := NewUserInfo(&github.User{Name: "Takayuki Nagatomi", AvatarURL: url}, NewTestRenderer(), theme.DarkTheme())
+			tt.update(&ui)
+
+			got := ui.View()
+			if strings.Contains(got, "AVATAR") != tt.want {
+				t.Errorf("UserInfo.View() = %v, want avatar shown %v", got, tt.want)
+			}
+			if tt.want && !strings.Contains(got, "  AVATAR") {
+				t.Errorf("UserInfo.View() = %v, want the avatar next to the Info section", got)
+			}
+		})
+	}
+}
+
+func TestUserInfoLinks(t *testing.T) {
+	user := &github.User{
+		Login:      "octocat",
+		Name:       "The Octocat",
+		Bio:        "Working at @github",
+		WebsiteURL: "example.com",
+		Social: []github.SocialAccount{
+			{Provider: "MASTODON", URL: "https://hachyderm.io/@octocat"},
+		},
+		Organizations: []github.Organization{
+			{Login: "cli", Name: "GitHub CLI"},
+		},
+	}
+	ui := NewUserInfo(user, NewTestRenderer(), theme.DarkTheme())
+	ui.View()
+
+	wantURLs := []string{
+		"https://github.com/github",
+		"https://example.com",
+		"https://github.com/cli",
+		"https://hachyderm.io/@octocat",
+	}
+	var gotURLs []string
+	for _, link := range ui.links {
+		gotURLs = append(gotURLs, link.URL)
+	}
+	if !slices.Equal(gotURLs, wantURLs) {
+		t.Fatalf("links = %v, want %v", gotURLs, wantURLs)
+	}
+
+	if _, ok := ui.SelectedLink(); ok {
+		t.Error("SelectedLink() ok = true before selecting a link, want false")
+	}
+
+	tests := []struct {
+		name     string
+		move     func(ui *UserInfo)
+		wantURL  string
+		wantLine int
+	}{
+		{
+			name:     "first link",
+			move:     func(ui *UserInfo) { ui.NextLink() },
+			wantURL:  "https://github.com/github",
+			wantLine: 2,
+		},
+		{
+			name:     "next link",
+			move:     func(ui *UserInfo) { ui.NextLink() },
+			wantURL:  "https://example.com",
+			wantLine: 3,
+		},
+		{
+			name:     "previous link",
+			move:     func(ui *UserInfo) { ui.PrevLink() },
+			wantURL:  "https://github.com/github",
+			wantLine: 2,
+		},
+		{
+			name:    "wrap around backwards",
+			move:    func(ui *UserInfo) { ui.PrevLink() },
+			wantURL: "https://hachyderm.io/@octocat",
+		},
+		{
+			name:    "wrap around forwards",
+			move:    func(ui *UserInfo) { ui.NextLink() },
+			wantURL: "https://github.com/github",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.move(&ui)
+			ui.View()
+			link, ok := ui.SelectedLink()
+			if !ok || link.URL != tt.wantURL {
+				t.Errorf("SelectedLink() = %v, %v, want %v", link.URL, ok, tt.wantURL)
+			}
+			if tt.wantLine > 0 && link.Line != tt.wantLine {
+				t.Errorf("SelectedLink() line = %v, want %v", link.Line, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestUserInfoHyperlinks(t *testing.T) {
+	user := &github.User{Name: "The Octocat", WebsiteURL: "https://example.com"}
+	ui := NewUserInfo(user, NewTestRenderer(), theme.DarkTheme())
+
+	hyperlink := "\x1b]8;;https://example.com"
+	if got := ui.View(); strings.Contains(got, hyperlink) {
+		t.Errorf("UserInfo.View() = %q, should not contain hyperlinks by default", got)
+	}
+
+	ui.SetHyperlinks(true)
+	if got := ui.View(); !strings.Contains(got, hyperlink) {
+		t.Errorf("UserInfo.View() = %q, want hyperlink %q", got, hyperlink)
+	}
+}
+
+func TestAccountAge(t *testing.T) {
+	now := time.Date(2025, time.June, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		created time.Time
+		want    string
+	}{
+		{
+			name:    "years",
+			created: time.Date(2010, time.March, 4, 0, 0, 0, 0, time.UTC),
+			want:    "15 years",
+		},
+		{
+			name:    "anniversary not reached yet",
+			created: time.Date(2024, time.June, 16, 0, 0, 0, 0, time.UTC),
+			want:    "11 months",
+		},
+		{
+			name:    "one year",
+			created: time.Date(2024, time.June, 15, 0, 0, 0, 0, time.UTC),
+			want:    "1 year",
+		},
+		{
+			name:    "days",
+			created: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+			want:    "14 days",
+		},
+		{
+			name:    "today",
+			created: time.Date(2025, time.June, 15, 8, 0, 0, 0, time.UTC),
+			want:    "today",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := accountAge(tt.created, now); got != tt.want {
+				t.Errorf("accountAge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// largeREADME returns a README with many sections, lists and code blocks
+func largeREADME() string {
+	var b strings.Builder
+	for i := range 200 {
+		fmt.Fprintf(&b, "## Section %d\n\n", i)
+		b.WriteString("Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua.\n\n")
+		b.WriteString("- First item\n- Second item with `code`\n- Third item with a [link](https://example.com)\n\n")
+		b.WriteString("```go\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n```\n\n")
+	}
+	return b.String()
+}
+
+func BenchmarkUserInfoView(b *testing.B) {
+	user := &github.User{
+		Name:   "Takayuki Nagatomi",
+		README: stringPtr(largeREADME()),
+	}
+
+	b.Run("unchanged", func(b *testing.B) {
+		ui := NewUserInfo(user, NewTestRenderer(), theme.DarkTheme())
+		renderREADME(&ui)
+		ui.View()
+		b.ResetTimer()
+		for range b.N {
+			ui.View()
+		}
+	})
+
+	b.Run("width change", func(b *testing.B) {
+		ui := NewUserInfo(user, NewTestRenderer(), theme.DarkTheme())
+		for i := range b.N {
+			ui.SetWidth(80 + i%2)
+			renderREADME(&ui)
+			ui.View()
+		}
+	})
+}
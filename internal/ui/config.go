@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"fmt"
+
+	chroma "github.com/alecthomas/chroma/v2/styles"
+	"github.com/tnagatomi/gh-portrait/internal/config"
+	"github.com/tnagatomi/gh-portrait/internal/termimage"
+	"github.com/tnagatomi/gh-portrait/internal/ui/components"
+	"github.com/tnagatomi/gh-portrait/internal/ui/theme"
+)
+
+// ValidateConfig checks the configuration values naming the themes, README styles,
+// code themes and image protocols of the UI, which config.Validate does not know
+func ValidateConfig(cfg config.Config) error {
+	if _, ok := cfg.Themes[cfg.Theme]; !ok && !theme.IsBuiltin(cfg.Theme) {
+		return fmt.Errorf("theme: unknown theme %q", cfg.Theme)
+	}
+	for name, custom := range cfg.Themes {
+		if theme.IsBuiltin(name) {
+			return fmt.Errorf("themes.%s: cannot redefine a built-in theme", name)
+		}
+		if custom.Base != "" && !theme.IsBuiltin(custom.Base) {
+			return fmt.Errorf("themes.%s.base: unknown built-in theme %q", name, custom.Base)
+		}
+		if err := components.ValidateStyle(custom.Glamour); err != nil {
+			return fmt.Errorf("themes.%s.glamour: %w", name, err)
+		}
+	}
+
+	if err := components.ValidateStyle(cfg.Renderer.Style); err != nil {
+		return fmt.Errorf("renderer.style: %w", err)
+	}
+	if cfg.Renderer.CodeTheme != "" && chroma.Registry[cfg.Renderer.CodeTheme] == nil {
+		return fmt.Errorf("renderer.code_theme: unknown theme %q", cfg.Renderer.CodeTheme)
+	}
+
+	if !termimage.IsValid(cfg.Avatar) {
+		return fmt.Errorf("avatar: unknown protocol %q", cfg.Avatar)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/tnagatomi/gh-portrait/internal/config"
+)
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			data: "",
+		},
+		{
+			name: "custom theme",
+			data: "theme: solarized\nthemes:\n  solarized:\n    base: dark\n    accent: \"#268bd2\"",
+		},
+		{
+			name:    "unknown theme",
+			data:    "theme: solarized",
+			wantErr: true,
+		},
+		{
+			name:    "custom theme with unknown base",
+			data:    "theme: mine\nthemes:\n  mine:\n    base: solarized",
+			wantErr: true,
+		},
+		{
+			name:    "custom theme redefining a built-in theme",
+			data:    "themes:\n  dark:\n    accent: \"1\"",
+			wantErr: true,
+		},
+		{
+			name:    "custom theme with unknown README style",
+			data:    "theme: mine\nthemes:\n  mine:\n    glamour: nonexistent",
+			wantErr: true,
+		},
+		{
+			name: "README style",
+			data: "renderer:\n  style: dracula",
+		},
+		{
+			name:    "unknown README style",
+			data:    "renderer:\n  style: nonexistent",
+			wantErr: true,
+		},
+		{
+			name: "code theme",
+			data: "renderer:\n  code_theme: monokai",
+		},
+		{
+			name:    "unknown code theme",
+			data:    "renderer:\n  code_theme: nonexistent",
+			wantErr: true,
+		},
+		{
+			name:    "unknown avatar protocol",
+			data:    "avatar: ascii",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.Parse([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if err := ValidateConfig(cfg); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/tnagatomi/gh-portrait/internal/config"
+	"github.com/tnagatomi/gh-portrait/internal/ui/components"
+)
+
+// keySymbols maps key names to the symbols shown in the help
+var keySymbols = map[string]string{
+	"up":    "↑",
+	"down":  "↓",
+	"left":  "←",
+	"right": "→",
+}
+
+// keyMap defines the key bindings of the application
+type keyMap struct {
+	Up         key.Binding
+	Down       key.Binding
+	NextTab    key.Binding
+	PrevTab    key.Binding
+	Open       key.Binding
+	Filter     key.Binding
+	Sort       key.Binding
+	Facets     key.Binding
+	Refresh    key.Binding
+	RefreshAll key.Binding
+	Focus      key.Binding
+	NextLink   key.Binding
+	PrevLink   key.Binding
+	Copy       key.Binding
+	Contents   key.Binding
+	Search     key.Binding
+	NextMatch  key.Binding
+	PrevMatch  key.Binding
+	Help       key.Binding
+	Quit       key.Binding
+}
+
+// newKeyMap creates the key bindings from the configuration
+func newKeyMap(keys config.Keys) keyMap {
+	return keyMap{
+		Up:         newBinding(keys.Up, "up"),
+		Down:       newBinding(keys.Down, "down"),
+		NextTab:    newBinding(keys.NextTab, "next tab"),
+		PrevTab:    newBinding(keys.PrevTab, "previous tab"),
+		Open:       newBinding(keys.Open, "open in browser"),
+		Filter:     newBinding(keys.Filter, "filter"),
+		Sort:       newBinding(keys.Sort, "sort"),
+		Facets:     newBinding(keys.Facets, "facets"),
+		Refresh:    newBinding(keys.Refresh, "refresh"),
+		RefreshAll: newBinding(keys.RefreshAll, "refresh all"),
+		Focus:      newBinding(keys.Focus, "switch pane"),
+		NextLink:   newBinding(keys.NextLink, "next link"),
+		PrevLink:   newBinding(keys.PrevLink, "previous link"),
+		Copy:       newBinding(keys.Copy, "copy link"),
+		Contents:   newBinding(keys.Contents, "contents"),
+		Search:     newBinding(keys.Filter, "search"),
+		NextMatch:  newBinding(keys.NextMatch, "next match"),
+		PrevMatch:  newBinding(keys.PrevMatch, "previous match"),
+		Help:       newBinding(keys.Help, "help"),
+		Quit:       newBinding(keys.Quit, "quit"),
+	}
+}
+
+// newBinding creates a key binding whose help lists every bound key
+func newBinding(keys []string, desc string) key.Binding {
+	symbols := make([]string, len(keys))
+	for i, k := range keys {
+		if symbol, ok := keySymbols[k]; ok {
+			k = symbol
+		}
+		symbols[i] = k
+	}
+
+	return key.NewBinding(
+		key.WithKeys(keys...),
+		key.WithHelp(strings.Join(symbols, "/"), desc),
+	)
+}
+
+// repositoryListKeyMap returns the bindings handled by repository lists. They are
+// always enabled since the lists only receive input while they are shown.
+func (k keyMap) repositoryListKeyMap() components.RepositoryListKeyMap {
+	keys := components.RepositoryListKeyMap{
+		Up:     k.Up,
+		Down:   k.Down,
+		Open:   k.Open,
+		Filter: k.Filter,
+		Sort:   k.Sort,
+		Facets: k.Facets,
+		Reserved: []key.Binding{
+			k.NextTab, k.PrevTab, k.Refresh, k.RefreshAll, k.Focus, k.NextLink, k.PrevLink,
+			k.Copy, k.Contents, k.Search, k.NextMatch, k.PrevMatch, k.Help, k.Quit,
+		},
+	}
+	keys.Up.SetEnabled(true)
+	keys.Down.SetEnabled(true)
+	keys.Open.SetEnabled(true)
+	keys.Filter.SetEnabled(true)
+	keys.Sort.SetEnabled(true)
+	keys.Facets.SetEnabled(true)
+	return keys
+}
+
+// ShortHelp returns the bindings shown in the footer
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.NextLink, k.Open, k.Copy, k.Contents, k.Search, k.NextMatch, k.Filter, k.Sort, k.Facets, k.Refresh, k.Focus, k.PrevTab, k.NextTab, k.Help, k.Quit}
+}
+
+// FullHelp returns the bindings shown in the help overlay
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.Open, k.Filter, k.Sort, k.Facets},
+		{k.NextLink, k.PrevLink, k.Copy, k.Contents},
+		{k.Search, k.NextMatch, k.PrevMatch},
+		{k.PrevTab, k.NextTab, k.Focus, k.Refresh, k.RefreshAll},
+		{k.Help, k.Quit},
+	}
+}
@@ -0,0 +1,43 @@
+package ui
+
+import "github.com/charmbracelet/lipgloss"
+
+const (
+	// minProfileWidth and maxProfileWidth bound the width of the profile pane
+	minProfileWidth = 40
+	maxProfileWidth = 64
+
+	// minContentWidth is the narrowest tab content shown next to the profile
+	minContentWidth = 40
+
+	// paneSeparator separates the profile pane from the tab content
+	paneSeparator = " │ "
+)
+
+// layout holds the size of the panes shown between the header and the footer
+type layout struct {
+	split        bool // Whether the profile is shown next to the tab content
+	profileWidth int  // Width of the profile, the whole width when not split
+	contentWidth int  // Width of the tab content
+	height       int  // Height of the panes
+}
+
+// newLayout fits the panes between the rendered header and footer. The profile is
+// shown next to the tab content when the terminal is at least splitWidth wide and
+// both panes fit, and splitWidth 0 never splits.
+func newLayout(width, height int, header, footer string, splitWidth int) layout {
+	l := layout{
+		profileWidth: width,
+		contentWidth: width,
+		height:       max(height-lipgloss.Height(header)-lipgloss.Height(footer), 0),
+	}
+	minWidth := minProfileWidth + lipgloss.Width(paneSeparator) + minContentWidth
+	if splitWidth == 0 || width < max(splitWidth, minWidth) {
+		return l
+	}
+
+	l.split = true
+	l.profileWidth = min(max(width*2/5, minProfileWidth), maxProfileWidth)
+	l.contentWidth = width - l.profileWidth - lipgloss.Width(paneSeparator)
+	return l
+}
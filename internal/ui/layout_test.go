@@ -0,0 +1,71 @@
+package ui
+
+import "testing"
+
+func TestNewLayout(t *testing.T) {
+	tests := []struct {
+		name       string
+		width      int
+		height     int
+		splitWidth int
+		want       layout
+	}{
+		{
+			name:       "narrower than the split width",
+			width:      100,
+			height:     30,
+			splitWidth: 120,
+			want:       layout{profileWidth: 100, contentWidth: 100, height: 28},
+		},
+		{
+			name:       "split",
+			width:      150,
+			height:     30,
+			splitWidth: 120,
+			want:       layout{split: true, profileWidth: 60, contentWidth: 87, height: 28},
+		},
+		{
+			name:       "profile width is bounded",
+			width:      200,
+			height:     30,
+			splitWidth: 120,
+			want:       layout{split: true, profileWidth: 64, contentWidth: 133, height: 28},
+		},
+		{
+			name:       "split disabled",
+			width:      200,
+			height:     30,
+			splitWidth: 0,
+			want:       layout{profileWidth: 200, contentWidth: 200, height: 28},
+		},
+		{
+			name:       "too narrow for both panes",
+			width:      30,
+			height:     30,
+			splitWidth: 1,
+			want:       layout{profileWidth: 30, contentWidth: 30, height: 28},
+		},
+		{
+			name:       "just wide enough for both panes",
+			width:      83,
+			height:     30,
+			splitWidth: 1,
+			want:       layout{split: true, profileWidth: 40, contentWidth: 40, height: 28},
+		},
+		{
+			name:       "shorter than the header and footer",
+			width:      100,
+			height:     1,
+			splitWidth: 0,
+			want:       layout{profileWidth: 100, contentWidth: 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newLayout(tt.width, tt.height, "header", "footer", tt.splitWidth); got != tt.want {
+				t.Errorf("newLayout() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+)
+
+// minVTEVersion is the first VTE version supporting hyperlinks, as reported by the
+// VTE_VERSION environment variable
+const minVTEVersion = 5000
+
+// supportsHyperlinks guesses from the environment whether the terminal supports
+// OSC 8 hyperlinks. Terminal multiplexers are assumed not to pass them through.
+func supportsHyperlinks(getenv func(string) string) bool {
+	if getenv("TMUX") != "" || strings.HasPrefix(getenv("TERM"), "screen") {
+		return false
+	}
+
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper":
+		return true
+	}
+	if version, err := strconv.Atoi(getenv("VTE_VERSION")); err == nil && version >= minVTEVersion {
+		return true
+	}
+	if getenv("KITTY_WINDOW_ID") != "" || getenv("WT_SESSION") != "" {
+		return true
+	}
+
+	term := getenv("TERM")
+	return term == "xterm-kitty" || term == "alacritty" || strings.HasPrefix(term, "foot")
+}
@@ -0,0 +1,61 @@
+package ui
+
+import "testing"
+
+func TestSupportsHyperlinks(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{
+			name: "unknown terminal",
+			env:  map[string]string{"TERM": "xterm-256color"},
+			want: false,
+		},
+		{
+			name: "terminal program",
+			env:  map[string]string{"TERM_PROGRAM": "WezTerm"},
+			want: true,
+		},
+		{
+			name: "recent VTE",
+			env:  map[string]string{"VTE_VERSION": "6003"},
+			want: true,
+		},
+		{
+			name: "old VTE",
+			env:  map[string]string{"VTE_VERSION": "4601"},
+			want: false,
+		},
+		{
+			name: "kitty",
+			env:  map[string]string{"KITTY_WINDOW_ID": "1"},
+			want: true,
+		},
+		{
+			name: "TERM",
+			env:  map[string]string{"TERM": "foot-extra"},
+			want: true,
+		},
+		{
+			name: "tmux",
+			env:  map[string]string{"TMUX": "/tmp/tmux-1000/default,1,0", "TERM_PROGRAM": "iTerm.app"},
+			want: false,
+		},
+		{
+			name: "screen",
+			env:  map[string]string{"TERM": "screen-256color", "KITTY_WINDOW_ID": "1"},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			if got := supportsHyperlinks(getenv); got != tt.want {
+				t.Errorf("supportsHyperlinks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
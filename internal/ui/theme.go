@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tnagatomi/gh-portrait/internal/config"
+	"github.com/tnagatomi/gh-portrait/internal/ui/theme"
+)
+
+// newTheme resolves the theme selected in the configuration, applying user-defined
+// colors on top of their base theme
+func newTheme(cfg config.Config) theme.Theme {
+	custom, ok := cfg.Themes[cfg.Theme]
+	if !ok {
+		th, err := theme.Builtin(cfg.Theme)
+		if err != nil {
+			th, _ = theme.Builtin(theme.Auto)
+		}
+		return th
+	}
+
+	th, err := theme.Builtin(custom.Base)
+	if err != nil {
+		th, _ = theme.Builtin(theme.Auto)
+	}
+	th.Name = cfg.Theme
+	if custom.Accent != "" {
+		th.Accent = lipgloss.Color(custom.Accent)
+	}
+	if custom.Muted != "" {
+		th.Muted = lipgloss.Color(custom.Muted)
+	}
+	if custom.Subtle != "" {
+		th.Subtle = lipgloss.Color(custom.Subtle)
+	}
+	if custom.Error != "" {
+		th.Error = lipgloss.Color(custom.Error)
+	}
+	if custom.Glamour != "" {
+		th.Glamour = custom.Glamour
+	}
+	return th
+}
+
+// styles holds the styles used by the application model
+type styles struct {
+	title     lipgloss.Style
+	divider   lipgloss.Style
+	error     lipgloss.Style
+	errorHelp lipgloss.Style
+}
+
+// newStyles creates the application styles for the given theme
+func newStyles(th theme.Theme) styles {
+	return styles{
+		title: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(th.Accent),
+		divider: lipgloss.NewStyle().
+			Foreground(th.Muted),
+		error: lipgloss.NewStyle().
+			Foreground(th.Error),
+		errorHelp: lipgloss.NewStyle().
+			Foreground(th.Subtle),
+	}
+}
@@ -0,0 +1,90 @@
+package theme
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Names of the built-in themes
+const (
+	Auto         = "auto"
+	Dark         = "dark"
+	Light        = "light"
+	HighContrast = "high-contrast"
+)
+
+// Theme defines the colors used across the UI
+type Theme struct {
+	Name    string
+	Accent  lipgloss.Color // Titles, active tab and selected items
+	Muted   lipgloss.Color // Inactive tabs, dividers and help text
+	Subtle  lipgloss.Color // Secondary text such as hints and descriptions
+	Error   lipgloss.Color // Error messages
+	Glamour string         // Glamour style used to render READMEs
+}
+
+// DarkTheme returns the theme for terminals with a dark background
+func DarkTheme() Theme {
+	return Theme{
+		Name:    Dark,
+		Accent:  lipgloss.Color("86"),
+		Muted:   lipgloss.Color("241"),
+		Subtle:  lipgloss.Color("243"),
+		Error:   lipgloss.Color("9"),
+		Glamour: "dark",
+	}
+}
+
+// LightTheme returns the theme for terminals with a light background
+func LightTheme() Theme {
+	return Theme{
+		Name:    Light,
+		Accent:  lipgloss.Color("30"),
+		Muted:   lipgloss.Color("246"),
+		Subtle:  lipgloss.Color("241"),
+		Error:   lipgloss.Color("160"),
+		Glamour: "light",
+	}
+}
+
+// HighContrastTheme returns a theme using only bright base colors
+func HighContrastTheme() Theme {
+	return Theme{
+		Name:    HighContrast,
+		Accent:  lipgloss.Color("11"),
+		Muted:   lipgloss.Color("15"),
+		Subtle:  lipgloss.Color("15"),
+		Error:   lipgloss.Color("9"),
+		Glamour: "dark",
+	}
+}
+
+// Builtin returns the built-in theme with the given name. The auto theme picks the
+// dark or light theme based on the terminal background.
+func Builtin(name string) (Theme, error) {
+	switch name {
+	case Auto, "":
+		if lipgloss.HasDarkBackground() {
+			return DarkTheme(), nil
+		}
+		return LightTheme(), nil
+	case Dark:
+		return DarkTheme(), nil
+	case Light:
+		return LightTheme(), nil
+	case HighContrast:
+		return HighContrastTheme(), nil
+	default:
+		return Theme{}, fmt.Errorf("unknown theme: %s", name)
+	}
+}
+
+// IsBuiltin reports whether name is one of the built-in themes
+func IsBuiltin(name string) bool {
+	switch name {
+	case Auto, Dark, Light, HighContrast:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package theme
+
+import "testing"
+
+func TestBuiltin(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Theme
+		wantErr bool
+	}{
+		{name: Dark, want: DarkTheme()},
+		{name: Light, want: LightTheme()},
+		{name: HighContrast, want: HighContrastTheme()},
+		{name: "solarized", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Builtin(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Builtin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Builtin() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuiltinAuto(t *testing.T) {
+	got, err := Builtin(Auto)
+	if err != nil {
+		t.Fatalf("Builtin() error = %v", err)
+	}
+	if got.Name != Dark && got.Name != Light {
+		t.Errorf("Builtin() name = %v, want %v or %v", got.Name, Dark, Light)
+	}
+}
@@ -1,37 +1,95 @@
 package main
 
 import (
-	"context"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
+	"github.com/tnagatomi/gh-portrait/internal/config"
 	"github.com/tnagatomi/gh-portrait/internal/github"
+	"github.com/tnagatomi/gh-portrait/internal/termimage"
 	"github.com/tnagatomi/gh-portrait/internal/ui"
 )
 
+const usage = `usage: gh portrait [--watch <interval>] [--no-avatar] [--style <style>] [--code-theme <theme>]
+                   [--wrap <width>] [--no-emoji] [--link-footnotes] [--] <username>
+       gh portrait export [--format csv|tsv] [--tab pinned|owning|contributed] [--details] <username>
+       gh portrait config`
+
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Fprintln(os.Stderr, "usage: gh portrait <username>")
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	github.SetCacheTTL(cfg.CacheTTL)
+
+	// Users named like a subcommand are shown with "gh portrait -- <username>",
+	// which the flag parser of runPortrait reads as the end of the flags
+	if len(os.Args) >= 2 {
+		switch os.Args[1] {
+		case "export":
+			os.Exit(runExport(os.Args[2:]))
+		case "config":
+			os.Exit(runConfig(cfg, os.Args[2:]))
+		}
+	}
+
+	os.Exit(runPortrait(cfg, os.Args[1:]))
+}
 
-	username := os.Args[1]
-	ctx := context.Background()
+// runPortrait starts the TUI for the user and returns the process exit code
+func runPortrait(cfg config.Config, args []string) int {
+	flags := flag.NewFlagSet("portrait", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	watch := flags.Duration("watch", cfg.Watch, "refresh everything at this interval, e.g. 5m")
+	noAvatar := flags.Bool("no-avatar", false, "do not draw the user's avatar or README images")
+	style := flags.String("style", cfg.Renderer.Style, "glamour style name or path to a JSON style for READMEs")
+	codeTheme := flags.String("code-theme", cfg.Renderer.CodeTheme, "chroma theme highlighting README code blocks")
+	wrap := flags.Int("wrap", cfg.Renderer.WrapWidth, "widest READMEs are wrapped at, 0 for the terminal width")
+	noEmoji := flags.Bool("no-emoji", false, "do not render emoji shortcodes in READMEs")
+	linkFootnotes := flags.Bool("link-footnotes", cfg.Renderer.LinkFootnotes, "list README link URLs as numbered footnotes")
 
-	// Fetch user information
-	user, err := github.FetchUser(ctx, username)
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 || *watch < 0 {
+		fmt.Fprintln(os.Stderr, usage)
+		return 1
+	}
+	cfg.Watch = *watch
+	if *noAvatar {
+		cfg.Avatar = string(termimage.None)
+	}
+	cfg.Renderer.Style = *style
+	cfg.Renderer.CodeTheme = *codeTheme
+	cfg.Renderer.WrapWidth = *wrap
+	cfg.Renderer.LinkFootnotes = *linkFootnotes
+	if *noEmoji {
+		cfg.Renderer.Emoji = false
+	}
+	err := cfg.Validate()
+	if err == nil {
+		err = ui.ValidateConfig(cfg)
+	}
 	if err != nil {
-		if strings.Contains(err.Error(), "Could not resolve to a User") {
-			fmt.Fprintf(os.Stderr, "Error: User '%s' not found\n", username)
-		} else {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		}
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
 	}
 
-	if err := ui.Start(user); err != nil {
+	username := flags.Arg(0)
+	if err := ui.Start(username, cfg); err != nil {
+		printError(username, err)
+		return 1
+	}
+
+	return 0
+}
+
+// printError reports an error from the GitHub API to stderr
+func printError(username string, err error) {
+	if strings.Contains(err.Error(), "Could not resolve to a User") {
+		fmt.Fprintf(os.Stderr, "Error: User '%s' not found\n", username)
+	} else {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
 	}
 }